@@ -0,0 +1,365 @@
+// Package anthropic provides an HTTP client for the Anthropic Messages API.
+//
+// Anthropic models do not accept a JSON schema response format, so structured
+// output is obtained by forcing the model to call a single tool whose input
+// schema is the litmus schema. The tool input is returned as the response.
+package anthropic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.carr.sh/litmus/internal/provider"
+	"go.carr.sh/litmus/internal/types"
+)
+
+const (
+	defaultBaseURL    = "https://api.anthropic.com/v1"
+	defaultVersion    = "2023-06-01"
+	defaultMaxTokens  = 4096
+	defaultMaxRetries = 3
+	defaultRetryDelay = time.Second
+	defaultTimeout    = 120 * time.Second
+
+	// toolName is the name of the tool the model is forced to call.
+	toolName = "response"
+	// wrapperField holds the value when a non-object schema is wrapped, as
+	// tool input schemas must describe an object.
+	wrapperField = "value"
+)
+
+// Client is an HTTP client for the Anthropic Messages API.
+type Client struct {
+	httpClient *http.Client
+	apiKey     string
+	baseURL    string
+	version    string
+	headers    map[string]string
+	maxTokens  int
+	maxRetries int
+	retryDelay time.Duration
+}
+
+var _ provider.Provider = (*Client)(nil)
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithBaseURL sets a custom base URL for the API.
+func WithBaseURL(url string) Option {
+	return func(c *Client) {
+		c.baseURL = strings.TrimSuffix(url, "/")
+	}
+}
+
+// WithHTTPClient sets a custom HTTP client.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithRetry configures retry behavior.
+func WithRetry(maxRetries int, retryDelay time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.retryDelay = retryDelay
+	}
+}
+
+// WithTimeout sets the HTTP client timeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.httpClient.Timeout = timeout
+	}
+}
+
+// WithHeaders sets extra HTTP headers sent with every request.
+func WithHeaders(headers map[string]string) Option {
+	return func(c *Client) {
+		for k, v := range headers {
+			c.headers[k] = v
+		}
+	}
+}
+
+// WithMaxTokens sets the maximum number of tokens to generate, which the
+// Messages API requires on every request.
+func WithMaxTokens(maxTokens int) Option {
+	return func(c *Client) {
+		c.maxTokens = maxTokens
+	}
+}
+
+// NewClient creates a new Anthropic API client.
+func NewClient(apiKey string, opts ...Option) *Client {
+	c := &Client{
+		httpClient: &http.Client{Timeout: defaultTimeout},
+		apiKey:     apiKey,
+		baseURL:    defaultBaseURL,
+		version:    defaultVersion,
+		headers:    make(map[string]string),
+		maxTokens:  defaultMaxTokens,
+		maxRetries: defaultMaxRetries,
+		retryDelay: defaultRetryDelay,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Message represents a conversation message.
+type Message struct {
+	// Role is the role of the message, either "user" or "assistant".
+	Role string `json:"role"`
+	// Content is the plain text content of the message.
+	Content string `json:"content"`
+}
+
+// Tool describes a tool the model may call.
+type Tool struct {
+	// Name is the name of the tool.
+	Name string `json:"name"`
+	// Description explains to the model what the tool is for.
+	Description string `json:"description,omitempty"`
+	// InputSchema is the JSON schema of the tool input.
+	InputSchema json.RawMessage `json:"input_schema"`
+}
+
+// ToolChoice controls how the model uses tools.
+type ToolChoice struct {
+	// Type is the kind of tool choice; "tool" forces a specific tool.
+	Type string `json:"type"`
+	// Name is the tool to call when Type is "tool".
+	Name string `json:"name,omitempty"`
+}
+
+// MessagesRequest represents a Messages API request.
+type MessagesRequest struct {
+	// Model is the name of the model to use.
+	Model string `json:"model"`
+	// MaxTokens is the maximum number of tokens to generate.
+	MaxTokens int `json:"max_tokens"`
+	// System is the system prompt.
+	System string `json:"system,omitempty"`
+	// Messages is the list of conversation messages.
+	Messages []Message `json:"messages"`
+	// Tools is the list of tools available to the model.
+	Tools []Tool `json:"tools,omitempty"`
+	// ToolChoice controls which tool the model calls.
+	ToolChoice *ToolChoice `json:"tool_choice,omitempty"`
+	// Temperature controls randomness.
+	Temperature *float64 `json:"temperature,omitempty"`
+	// TopP is the nucleus sampling probability mass.
+	TopP *float64 `json:"top_p,omitempty"`
+	// StopSequences is a list of sequences that end generation.
+	StopSequences []string `json:"stop_sequences,omitempty"`
+}
+
+// ContentBlock is a single block of response content.
+type ContentBlock struct {
+	// Type is the block type, e.g. "text" or "tool_use".
+	Type string `json:"type"`
+	// Text is the text of a "text" block.
+	Text string `json:"text,omitempty"`
+	// Name is the tool name of a "tool_use" block.
+	Name string `json:"name,omitempty"`
+	// Input is the tool input of a "tool_use" block.
+	Input json.RawMessage `json:"input,omitempty"`
+}
+
+// Usage represents token usage information.
+type Usage struct {
+	// InputTokens is the number of uncached input tokens.
+	InputTokens int `json:"input_tokens"`
+	// OutputTokens is the number of generated tokens.
+	OutputTokens int `json:"output_tokens"`
+	// CacheCreationInputTokens is the number of input tokens written to the prompt cache.
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	// CacheReadInputTokens is the number of input tokens read from the prompt cache.
+	CacheReadInputTokens int `json:"cache_read_input_tokens"`
+}
+
+// MessagesResponse represents a Messages API response.
+type MessagesResponse struct {
+	// ID is the ID of the response.
+	ID string `json:"id"`
+	// Model is the name of the model that completed the request.
+	Model string `json:"model"`
+	// Content is the list of content blocks.
+	Content []ContentBlock `json:"content"`
+	// StopReason is the reason the model stopped generating.
+	StopReason string `json:"stop_reason"`
+	// Usage is the token usage for the request.
+	Usage Usage `json:"usage"`
+}
+
+// Complete sends a Messages API request that forces structured output via a
+// tool call.
+func (c *Client) Complete(ctx context.Context, r provider.Request) (*provider.CompletionResult, error) {
+	inputSchema, wrapped, err := toolSchema(r.Schema)
+	if err != nil {
+		return nil, err
+	}
+
+	req := MessagesRequest{
+		Model:     r.Model,
+		MaxTokens: c.maxTokens,
+		System:    r.SystemPrompt,
+		Messages: []Message{
+			{Role: "user", Content: r.UserInput},
+		},
+		Tools: []Tool{{
+			Name:        toolName,
+			Description: "Respond with structured output that conforms to the input schema.",
+			InputSchema: inputSchema,
+		}},
+		ToolChoice:    &ToolChoice{Type: "tool", Name: toolName},
+		Temperature:   r.Params.Temperature,
+		TopP:          r.Params.TopP,
+		StopSequences: r.Params.Stop,
+	}
+	if r.Params.MaxTokens != nil {
+		req.MaxTokens = *r.Params.MaxTokens
+	}
+
+	result, err := provider.Retry(ctx, c.maxRetries, c.retryDelay, func() (*provider.CompletionResult, error) {
+		return c.doRequest(ctx, req, wrapped)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Warnings = unsupportedParams(r.Params)
+	return result, nil
+}
+
+// unsupportedParams returns warnings for sampling parameters that the
+// Messages API does not accept and were therefore not sent.
+func unsupportedParams(p types.Params) []string {
+	var warnings []string
+	if p.Seed != nil {
+		warnings = append(warnings, "anthropic: seed is not supported and was ignored")
+	}
+	if p.FrequencyPenalty != nil {
+		warnings = append(warnings, "anthropic: frequency_penalty is not supported and was ignored")
+	}
+	if p.PresencePenalty != nil {
+		warnings = append(warnings, "anthropic: presence_penalty is not supported and was ignored")
+	}
+	return warnings
+}
+
+func (c *Client) doRequest(ctx context.Context, msgReq MessagesRequest, wrapped bool) (*provider.CompletionResult, error) {
+	body, err := json.Marshal(msgReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/messages", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-api-key", c.apiKey)
+	req.Header.Set("anthropic-version", c.version)
+	for k, v := range c.headers {
+		req.Header.Set(k, v)
+	}
+
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	latency := time.Since(start)
+
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(respBody))
+	}
+
+	var msgResp MessagesResponse
+	if err := json.Unmarshal(respBody, &msgResp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	input, err := toolInput(msgResp.Content, wrapped)
+	if err != nil {
+		return nil, err
+	}
+
+	return &provider.CompletionResult{
+		Response:         input,
+		Provider:         "Anthropic",
+		TokensIn:         msgResp.Usage.InputTokens,
+		TokensOut:        msgResp.Usage.OutputTokens,
+		CacheReadTokens:  msgResp.Usage.CacheReadInputTokens,
+		CacheWriteTokens: msgResp.Usage.CacheCreationInputTokens,
+		Latency:          latency,
+	}, nil
+}
+
+// toolSchema returns the tool input schema for a litmus schema. Tool inputs
+// must be objects, so any other schema is wrapped in a single-field object.
+func toolSchema(schema json.RawMessage) (json.RawMessage, bool, error) {
+	var s map[string]any
+	if err := json.Unmarshal(schema, &s); err != nil {
+		return nil, false, fmt.Errorf("failed to parse schema: %w", err)
+	}
+
+	if s["type"] == "object" {
+		return schema, false, nil
+	}
+
+	wrapped, err := json.Marshal(map[string]any{
+		"type":                 "object",
+		"properties":           map[string]any{wrapperField: schema},
+		"required":             []string{wrapperField},
+		"additionalProperties": false,
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to wrap schema: %w", err)
+	}
+	return wrapped, true, nil
+}
+
+// toolInput extracts the forced tool call input from the response content,
+// unwrapping it if the schema was wrapped.
+func toolInput(content []ContentBlock, wrapped bool) (json.RawMessage, error) {
+	for _, block := range content {
+		if block.Type != "tool_use" || block.Name != toolName {
+			continue
+		}
+
+		if !wrapped {
+			return block.Input, nil
+		}
+
+		var input map[string]json.RawMessage
+		if err := json.Unmarshal(block.Input, &input); err != nil {
+			return nil, fmt.Errorf("failed to parse tool input: %w", err)
+		}
+		value, ok := input[wrapperField]
+		if !ok {
+			return nil, fmt.Errorf("tool input missing %q field", wrapperField)
+		}
+		return value, nil
+	}
+
+	return nil, fmt.Errorf("no %s tool call in response", toolName)
+}
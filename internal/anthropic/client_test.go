@@ -0,0 +1,142 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.carr.sh/litmus/internal/provider"
+)
+
+func TestComplete(t *testing.T) {
+	tests := []struct {
+		name string
+		// schema is the litmus schema of the request.
+		schema string
+		// input is the tool input returned by the stand-in server.
+		input string
+		// wantSchema is the tool input schema the client should send.
+		wantSchema string
+		// want is the response the client should return.
+		want string
+	}{
+		{
+			name:       "object schema",
+			schema:     `{"type":"object","properties":{"name":{"type":"string"}}}`,
+			input:      `{"name":"Ada"}`,
+			wantSchema: `{"type":"object","properties":{"name":{"type":"string"}}}`,
+			want:       `{"name":"Ada"}`,
+		},
+		{
+			name:       "array schema",
+			schema:     `{"type":"array","items":{"type":"string"}}`,
+			input:      `{"value":["a","b"]}`,
+			wantSchema: `{"type":"object","properties":{"value":{"type":"array","items":{"type":"string"}}},"required":["value"],"additionalProperties":false}`,
+			want:       `["a","b"]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got MessagesRequest
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/messages" {
+					t.Errorf("path = %q, want /messages", r.URL.Path)
+				}
+				if key := r.Header.Get("x-api-key"); key != "test-key" {
+					t.Errorf("x-api-key = %q, want test-key", key)
+				}
+				if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+					t.Errorf("failed to decode request: %v", err)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(MessagesResponse{
+					Content: []ContentBlock{
+						{Type: "text", Text: "Calling the tool."},
+						{Type: "tool_use", Name: toolName, Input: json.RawMessage(tt.input)},
+					},
+					Usage: Usage{
+						InputTokens:              12,
+						OutputTokens:             5,
+						CacheCreationInputTokens: 100,
+						CacheReadInputTokens:     200,
+					},
+				})
+			}))
+			defer srv.Close()
+
+			c := NewClient("test-key", WithBaseURL(srv.URL), WithRetry(1, 0))
+			result, err := c.Complete(context.Background(), provider.Request{
+				Model:        "claude-test",
+				SystemPrompt: "Extract the fields.",
+				UserInput:    "Ada Lovelace",
+				Schema:       json.RawMessage(tt.schema),
+			})
+			if err != nil {
+				t.Fatalf("Complete() error = %v", err)
+			}
+
+			if got.ToolChoice == nil || got.ToolChoice.Type != "tool" || got.ToolChoice.Name != toolName {
+				t.Errorf("tool_choice = %+v, want tool %q", got.ToolChoice, toolName)
+			}
+			if len(got.Tools) != 1 || got.Tools[0].Name != toolName {
+				t.Fatalf("tools = %+v, want one %q tool", got.Tools, toolName)
+			}
+			assertJSON(t, "input_schema", got.Tools[0].InputSchema, tt.wantSchema)
+			if got.System != "Extract the fields." || len(got.Messages) != 1 || got.Messages[0].Content != "Ada Lovelace" {
+				t.Errorf("system = %q, messages = %+v", got.System, got.Messages)
+			}
+			if got.MaxTokens != defaultMaxTokens {
+				t.Errorf("max_tokens = %d, want %d", got.MaxTokens, defaultMaxTokens)
+			}
+
+			assertJSON(t, "response", result.Response, tt.want)
+			if result.TokensIn != 12 || result.TokensOut != 5 {
+				t.Errorf("tokens = %d in, %d out, want 12 in, 5 out", result.TokensIn, result.TokensOut)
+			}
+			if result.CacheWriteTokens != 100 || result.CacheReadTokens != 200 {
+				t.Errorf("cache tokens = %d written, %d read, want 100 written, 200 read", result.CacheWriteTokens, result.CacheReadTokens)
+			}
+			if result.Cost != nil {
+				t.Errorf("cost = %v, want unknown", *result.Cost)
+			}
+		})
+	}
+}
+
+func TestCompleteWithoutToolCall(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(MessagesResponse{
+			Content: []ContentBlock{{Type: "text", Text: "I can't help with that."}},
+		})
+	}))
+	defer srv.Close()
+
+	c := NewClient("test-key", WithBaseURL(srv.URL), WithRetry(1, 0))
+	_, err := c.Complete(context.Background(), provider.Request{
+		Model:  "claude-test",
+		Schema: json.RawMessage(`{"type":"object"}`),
+	})
+	if err == nil {
+		t.Fatal("Complete() error = nil, want an error for a response without a tool call")
+	}
+}
+
+// assertJSON fails the test if got and want are not equal JSON values.
+func assertJSON(t *testing.T, name string, got json.RawMessage, want string) {
+	t.Helper()
+	var g, w any
+	if err := json.Unmarshal(got, &g); err != nil {
+		t.Fatalf("%s: invalid JSON %s: %v", name, got, err)
+	}
+	if err := json.Unmarshal([]byte(want), &w); err != nil {
+		t.Fatalf("%s: invalid expected JSON %s: %v", name, want, err)
+	}
+	gb, _ := json.Marshal(g)
+	wb, _ := json.Marshal(w)
+	if string(gb) != string(wb) {
+		t.Errorf("%s = %s, want %s", name, gb, wb)
+	}
+}
@@ -0,0 +1,107 @@
+// Package cache provides a content-addressed on-disk response cache that sits
+// in front of a provider, so identical requests are only paid for once.
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.carr.sh/litmus/internal/provider"
+	"go.carr.sh/litmus/internal/util"
+)
+
+// entry is a cached response, stored as one JSON file per request key.
+type entry struct {
+	// CreatedAt is when the response was cached.
+	CreatedAt time.Time `json:"created_at"`
+	// Response is the cached response.
+	Response *provider.CompletionResult `json:"response"`
+}
+
+// Cache wraps a provider and stores its responses on disk.
+type Cache struct {
+	// dir is the cache directory.
+	dir string
+	// ttl is how long entries remain valid; zero means forever.
+	ttl time.Duration
+	// next is the provider that serves cache misses.
+	next provider.Provider
+}
+
+var _ provider.Provider = (*Cache)(nil)
+
+// New creates a Cache in dir, creating the directory if needed. Entries older
+// than ttl are ignored and refreshed; a ttl of zero never expires entries.
+func New(dir string, ttl time.Duration, next provider.Provider) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	return &Cache{dir: dir, ttl: ttl, next: next}, nil
+}
+
+// DefaultDir returns the default cache directory inside the user's cache
+// directory.
+func DefaultDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "litmus", "responses"), nil
+}
+
+// Complete returns a cached response if one exists and has not expired, and
+// otherwise forwards the request and caches the response. Cached responses
+// are marked with Cached so callers can exclude them from latency metrics.
+func (c *Cache) Complete(ctx context.Context, req provider.Request) (*provider.CompletionResult, error) {
+	path := filepath.Join(c.dir, req.Key()+".json")
+
+	if result, ok := c.load(path); ok {
+		return result, nil
+	}
+
+	result, err := c.next.Complete(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// A failed cache write shouldn't fail the test; the next run will
+	// simply miss.
+	_ = c.store(path, result)
+	return result, nil
+}
+
+// load reads a cache entry, reporting false if it is missing, unreadable or
+// expired.
+func (c *Cache) load(path string) (*provider.CompletionResult, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil || e.Response == nil {
+		return nil, false
+	}
+
+	if c.ttl > 0 && time.Since(e.CreatedAt) > c.ttl {
+		return nil, false
+	}
+
+	// Nothing is spent on a cached response
+	e.Response.Cached = true
+	e.Response.Cost = new(float64)
+	return e.Response, true
+}
+
+// store writes a cache entry.
+func (c *Cache) store(path string, result *provider.CompletionResult) error {
+	data, err := json.Marshal(entry{CreatedAt: time.Now(), Response: result})
+	if err != nil {
+		return err
+	}
+	return util.WriteFileAtomic(path, data)
+}
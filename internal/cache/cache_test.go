@@ -0,0 +1,212 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.carr.sh/litmus/internal/provider"
+	"go.carr.sh/litmus/internal/types"
+)
+
+// countingProvider returns a fixed response and counts its calls.
+type countingProvider struct {
+	mu    sync.Mutex
+	calls int
+}
+
+func (p *countingProvider) Complete(ctx context.Context, req provider.Request) (*provider.CompletionResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls++
+	cost := 0.01
+	return &provider.CompletionResult{
+		Response: json.RawMessage(`{"name":"Ada"}`),
+		Latency:  time.Second,
+		Cost:     &cost,
+	}, nil
+}
+
+func TestCacheHit(t *testing.T) {
+	next := &countingProvider{}
+	c, err := New(t.TempDir(), time.Hour, next)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	req := provider.Request{Model: "gpt-test", UserInput: "Ada Lovelace", Schema: json.RawMessage(`{"type":"object"}`)}
+
+	first, err := c.Complete(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if first.Cached || first.Cost == nil || *first.Cost != 0.01 {
+		t.Errorf("first response = %+v, want a live response costing 0.01", first)
+	}
+
+	second, err := c.Complete(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if !second.Cached || second.Cost == nil || *second.Cost != 0 || string(second.Response) != `{"name":"Ada"}` {
+		t.Errorf("second response = %+v, want a free cached response", second)
+	}
+	if next.calls != 1 {
+		t.Errorf("provider called %d times, want 1", next.calls)
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	temperature := 0.5
+	base := provider.Request{Model: "gpt-test", SystemPrompt: "Extract.", UserInput: "Ada", Schema: json.RawMessage(`{"type":"object"}`)}
+
+	tests := []struct {
+		name string
+		req  provider.Request
+		// hit is true if the request should be served from base's entry.
+		hit bool
+	}{
+		{"same request", base, true},
+		{"reformatted schema", provider.Request{Model: "gpt-test", SystemPrompt: "Extract.", UserInput: "Ada", Schema: json.RawMessage("{\n  \"type\": \"object\"\n}")}, true},
+		{"different model", provider.Request{Model: "gpt-other", SystemPrompt: "Extract.", UserInput: "Ada", Schema: base.Schema}, false},
+		{"different prompt", provider.Request{Model: "gpt-test", SystemPrompt: "Summarize.", UserInput: "Ada", Schema: base.Schema}, false},
+		{"different input", provider.Request{Model: "gpt-test", SystemPrompt: "Extract.", UserInput: "Alan", Schema: base.Schema}, false},
+		{"different schema", provider.Request{Model: "gpt-test", SystemPrompt: "Extract.", UserInput: "Ada", Schema: json.RawMessage(`{"type":"array"}`)}, false},
+		{"different params", provider.Request{Model: "gpt-test", SystemPrompt: "Extract.", UserInput: "Ada", Schema: base.Schema, Params: types.Params{Temperature: &temperature}}, false},
+		{"different trial", provider.Request{Model: "gpt-test", SystemPrompt: "Extract.", UserInput: "Ada", Schema: base.Schema, Trial: 1}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := &countingProvider{}
+			c, err := New(t.TempDir(), 0, next)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			if _, err := c.Complete(context.Background(), base); err != nil {
+				t.Fatalf("Complete() error = %v", err)
+			}
+
+			result, err := c.Complete(context.Background(), tt.req)
+			if err != nil {
+				t.Fatalf("Complete() error = %v", err)
+			}
+			if result.Cached != tt.hit {
+				t.Errorf("Cached = %v, want %v", result.Cached, tt.hit)
+			}
+		})
+	}
+}
+
+func TestCacheTTL(t *testing.T) {
+	tests := []struct {
+		name string
+		ttl  time.Duration
+		age  time.Duration
+		hit  bool
+	}{
+		{"fresh", time.Hour, time.Minute, true},
+		{"expired", time.Hour, 2 * time.Hour, false},
+		{"no expiry", 0, 365 * 24 * time.Hour, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			next := &countingProvider{}
+			c, err := New(dir, tt.ttl, next)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			req := provider.Request{Model: "gpt-test", UserInput: "Ada"}
+
+			// Age the entry by rewriting its creation time
+			path := filepath.Join(dir, req.Key()+".json")
+			result, _ := next.Complete(context.Background(), req)
+			data, _ := json.Marshal(entry{CreatedAt: time.Now().Add(-tt.age), Response: result})
+			if err := os.WriteFile(path, data, 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := c.Complete(context.Background(), req)
+			if err != nil {
+				t.Fatalf("Complete() error = %v", err)
+			}
+			if got.Cached != tt.hit {
+				t.Errorf("Cached = %v, want %v", got.Cached, tt.hit)
+			}
+
+			// An expired entry is refreshed
+			if !tt.hit {
+				if again, _ := c.Complete(context.Background(), req); !again.Cached {
+					t.Error("expired entry was not refreshed")
+				}
+			}
+		})
+	}
+}
+
+func TestCacheCorruptEntry(t *testing.T) {
+	dir := t.TempDir()
+	next := &countingProvider{}
+	c, err := New(dir, 0, next)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	req := provider.Request{Model: "gpt-test", UserInput: "Ada"}
+	if err := os.WriteFile(filepath.Join(dir, req.Key()+".json"), []byte(`{"created_at":`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := c.Complete(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if got.Cached || next.calls != 1 {
+		t.Errorf("Cached = %v after %d calls, want a live response for a corrupt entry", got.Cached, next.calls)
+	}
+}
+
+func TestCacheConcurrentWrites(t *testing.T) {
+	dir := t.TempDir()
+	c, err := New(dir, 0, &countingProvider{})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	req := provider.Request{Model: "gpt-test", UserInput: "Ada"}
+
+	// Concurrent writers of the same entry must never leave a partial file
+	// or temporary files behind
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.Complete(context.Background(), req); err != nil {
+				t.Errorf("Complete() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != req.Key()+".json" {
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		t.Fatalf("cache directory = %s, want only the entry", strings.Join(names, ", "))
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, entries[0].Name()))
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil || e.Response == nil {
+		t.Errorf("entry = %s, want a complete entry: %v", data, err)
+	}
+}
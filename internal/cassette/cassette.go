@@ -0,0 +1,118 @@
+// Package cassette records provider responses to disk and replays them, so
+// runs can be re-scored and tested without a live endpoint.
+package cassette
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"go.carr.sh/litmus/internal/provider"
+	"go.carr.sh/litmus/internal/util"
+)
+
+// ErrMiss is returned when replaying a request that was never recorded.
+var ErrMiss = errors.New("no recorded response")
+
+// Entry is a recorded request/response pair, stored as one JSON file per
+// request named after the request key.
+type Entry struct {
+	// Request is the request that was sent.
+	Request provider.Request `json:"request"`
+	// Response is the response that was received.
+	Response *provider.CompletionResult `json:"response"`
+}
+
+// Recorder wraps a provider and saves every successful response to a
+// cassette directory.
+type Recorder struct {
+	// dir is the cassette directory.
+	dir string
+	// next is the provider that serves the requests.
+	next provider.Provider
+}
+
+var _ provider.Provider = (*Recorder)(nil)
+
+// NewRecorder creates a Recorder that writes to dir, creating it if needed.
+func NewRecorder(dir string, next provider.Provider) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cassette directory: %w", err)
+	}
+	return &Recorder{dir: dir, next: next}, nil
+}
+
+// Complete forwards the request and records the response.
+func (r *Recorder) Complete(ctx context.Context, req provider.Request) (*provider.CompletionResult, error) {
+	result, err := r.next.Complete(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := write(r.dir, Entry{Request: req, Response: result}); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Player serves responses from a cassette directory without contacting any
+// provider.
+type Player struct {
+	// dir is the cassette directory.
+	dir string
+}
+
+var _ provider.Provider = (*Player)(nil)
+
+// NewPlayer creates a Player that reads from dir.
+func NewPlayer(dir string) (*Player, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cassette directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("cassette path %s is not a directory", dir)
+	}
+	return &Player{dir: dir}, nil
+}
+
+// Complete returns the recorded response for the request, or an error
+// wrapping ErrMiss if there is none.
+func (p *Player) Complete(ctx context.Context, req provider.Request) (*provider.CompletionResult, error) {
+	key := req.Key()
+
+	data, err := os.ReadFile(filepath.Join(p.dir, key+".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w for model %s (key %s): re-record with --record", ErrMiss, req.Model, key[:12])
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette %s: %w", key, err)
+	}
+	if entry.Response == nil {
+		return nil, fmt.Errorf("cassette %s has no response", key)
+	}
+
+	return entry.Response, nil
+}
+
+// write stores an entry, replacing any existing recording for the request.
+func write(dir string, entry Entry) error {
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %w", err)
+	}
+
+	path := filepath.Join(dir, entry.Request.Key()+".json")
+	if err := util.WriteFileAtomic(path, data); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
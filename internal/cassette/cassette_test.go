@@ -0,0 +1,137 @@
+package cassette
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.carr.sh/litmus/internal/provider"
+)
+
+// stubProvider returns a fixed response, or an error, and counts its calls.
+type stubProvider struct {
+	result *provider.CompletionResult
+	err    error
+	calls  int
+}
+
+func (s *stubProvider) Complete(ctx context.Context, req provider.Request) (*provider.CompletionResult, error) {
+	s.calls++
+	return s.result, s.err
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cassettes")
+	cost := 0.002
+	live := &stubProvider{result: &provider.CompletionResult{
+		Response:  json.RawMessage(`{"name":"Ada"}`),
+		Provider:  "OpenAI",
+		TokensIn:  12,
+		TokensOut: 5,
+		Latency:   300 * time.Millisecond,
+		Cost:      &cost,
+	}}
+	req := provider.Request{
+		Model:        "gpt-test",
+		SystemPrompt: "Extract the fields.",
+		UserInput:    "Ada Lovelace",
+		Schema:       json.RawMessage(`{"type":"object"}`),
+	}
+
+	recorder, err := NewRecorder(dir, live)
+	if err != nil {
+		t.Fatalf("NewRecorder() error = %v", err)
+	}
+	if _, err := recorder.Complete(context.Background(), req); err != nil {
+		t.Fatalf("Recorder.Complete() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, req.Key()+".json")); err != nil {
+		t.Fatalf("cassette not written: %v", err)
+	}
+
+	player, err := NewPlayer(dir)
+	if err != nil {
+		t.Fatalf("NewPlayer() error = %v", err)
+	}
+	got, err := player.Complete(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Player.Complete() error = %v", err)
+	}
+	var response bytes.Buffer
+	if err := json.Compact(&response, got.Response); err != nil || response.String() != `{"name":"Ada"}` {
+		t.Errorf("replayed response %s, want {\"name\":\"Ada\"}", got.Response)
+	}
+	if got.Provider != "OpenAI" || got.TokensIn != 12 || got.TokensOut != 5 ||
+		got.Latency != 300*time.Millisecond || got.Cost == nil || *got.Cost != cost {
+		t.Errorf("replayed %+v, want the recorded response %+v", got, live.result)
+	}
+	if live.calls != 1 {
+		t.Errorf("provider called %d times, want 1", live.calls)
+	}
+}
+
+func TestReplayMiss(t *testing.T) {
+	dir := t.TempDir()
+	recorder, err := NewRecorder(dir, &stubProvider{result: &provider.CompletionResult{Response: json.RawMessage(`{}`)}})
+	if err != nil {
+		t.Fatalf("NewRecorder() error = %v", err)
+	}
+	recorded := provider.Request{Model: "gpt-test", UserInput: "recorded"}
+	if _, err := recorder.Complete(context.Background(), recorded); err != nil {
+		t.Fatalf("Recorder.Complete() error = %v", err)
+	}
+
+	player, err := NewPlayer(dir)
+	if err != nil {
+		t.Fatalf("NewPlayer() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		req  provider.Request
+	}{
+		{"different input", provider.Request{Model: "gpt-test", UserInput: "new"}},
+		{"different model", provider.Request{Model: "gpt-other", UserInput: "recorded"}},
+		{"different trial", provider.Request{Model: "gpt-test", UserInput: "recorded", Trial: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := player.Complete(context.Background(), tt.req)
+			if !errors.Is(err, ErrMiss) {
+				t.Fatalf("Player.Complete() error = %v, want ErrMiss", err)
+			}
+			if !strings.Contains(err.Error(), tt.req.Model) || !strings.Contains(err.Error(), tt.req.Key()[:12]) {
+				t.Errorf("error %q does not name the model and key", err)
+			}
+		})
+	}
+}
+
+func TestRecorderSkipsErrors(t *testing.T) {
+	dir := t.TempDir()
+	recorder, err := NewRecorder(dir, &stubProvider{err: errors.New("rate limited")})
+	if err != nil {
+		t.Fatalf("NewRecorder() error = %v", err)
+	}
+	if _, err := recorder.Complete(context.Background(), provider.Request{Model: "gpt-test"}); err == nil {
+		t.Fatal("Recorder.Complete() error = nil, want the provider error")
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Errorf("cassette directory has %d entries, want none for a failed request", len(entries))
+	}
+}
+
+func TestNewPlayerMissingDirectory(t *testing.T) {
+	if _, err := NewPlayer(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("NewPlayer() error = nil, want an error for a missing directory")
+	}
+}
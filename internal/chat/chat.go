@@ -0,0 +1,190 @@
+// Package chat implements the OpenAI-compatible chat completions protocol
+// shared by the OpenAI and OpenRouter clients.
+package chat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"go.carr.sh/litmus/internal/provider"
+	"go.carr.sh/litmus/internal/types"
+)
+
+// Message represents a chat message.
+type Message struct {
+	// Role is the role of the message, either "system" or "user".
+	Role string `json:"role"`
+	// Content is the plain text content of the message.
+	Content string `json:"content"`
+}
+
+// ResponseFormat specifies the structured output format.
+type ResponseFormat struct {
+	// Type is the type of response format, either "json_schema" or "json_object".
+	Type string `json:"type"`
+	// JSONSchema is the wrapped schema for the "json_schema" type.
+	JSONSchema json.RawMessage `json:"json_schema,omitempty"`
+	// Schema is the bare schema for the "json_object" type.
+	Schema json.RawMessage `json:"schema,omitempty"`
+}
+
+// JSONSchemaFormat returns a strict "json_schema" response format for a
+// schema.
+func JSONSchemaFormat(schema json.RawMessage) (*ResponseFormat, error) {
+	wrapped, err := json.Marshal(map[string]any{
+		"name":   "response",
+		"strict": true,
+		"schema": schema,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to wrap schema: %w", err)
+	}
+	return &ResponseFormat{Type: "json_schema", JSONSchema: wrapped}, nil
+}
+
+// Request represents a chat completion request.
+type Request struct {
+	// Model is the name of the model to use.
+	Model string `json:"model"`
+	// Messages is the list of messages to send to the model.
+	Messages []Message `json:"messages"`
+	// ResponseFormat is the response format for the model.
+	ResponseFormat *ResponseFormat `json:"response_format,omitempty"`
+	// Temperature controls randomness.
+	Temperature *float64 `json:"temperature,omitempty"`
+	// TopP is the nucleus sampling probability mass.
+	TopP *float64 `json:"top_p,omitempty"`
+	// Seed requests deterministic sampling.
+	Seed *int `json:"seed,omitempty"`
+	// MaxTokens is the maximum number of tokens to generate.
+	MaxTokens *int `json:"max_tokens,omitempty"`
+	// Stop is a list of sequences that end generation.
+	Stop []string `json:"stop,omitempty"`
+	// FrequencyPenalty penalises frequently repeated tokens.
+	FrequencyPenalty *float64 `json:"frequency_penalty,omitempty"`
+	// PresencePenalty penalises tokens that have already appeared.
+	PresencePenalty *float64 `json:"presence_penalty,omitempty"`
+	// Usage requests usage accounting, including cost, in the response. It
+	// is understood by OpenRouter.
+	Usage *UsageOptions `json:"usage,omitempty"`
+}
+
+// UsageOptions configures usage accounting.
+type UsageOptions struct {
+	// Include requests the cost of the request in the response usage.
+	Include bool `json:"include"`
+}
+
+// NewRequest creates a request with a system and a user message and the
+// given sampling parameters.
+func NewRequest(model, systemPrompt, userInput string, params types.Params) Request {
+	return Request{
+		Model: model,
+		Messages: []Message{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: userInput},
+		},
+		Temperature:      params.Temperature,
+		TopP:             params.TopP,
+		Seed:             params.Seed,
+		MaxTokens:        params.MaxTokens,
+		Stop:             params.Stop,
+		FrequencyPenalty: params.FrequencyPenalty,
+		PresencePenalty:  params.PresencePenalty,
+	}
+}
+
+// Usage represents token usage information.
+type Usage struct {
+	// PromptTokens is the number of tokens in the prompt.
+	PromptTokens int `json:"prompt_tokens"`
+	// CompletionTokens is the number of tokens in the completion.
+	CompletionTokens int `json:"completion_tokens"`
+	// Cost is the cost of the request in USD. OpenAI does not report it,
+	// but OpenRouter and some other gateways do.
+	Cost *float64 `json:"cost"`
+}
+
+// Choice represents a single completion choice.
+type Choice struct {
+	// Index is the index of the choice, zero-based.
+	Index int `json:"index"`
+	// Message is the message for the choice.
+	Message Message `json:"message"`
+}
+
+// Response represents a chat completion response.
+type Response struct {
+	// ID is the ID of the response.
+	ID string `json:"id"`
+	// Model is the name of the model that completed the request.
+	Model string `json:"model"`
+	// Provider is the upstream provider that completed the request, as
+	// reported by gateways such as OpenRouter.
+	Provider string `json:"provider"`
+	// Choices is the list of choices from the model.
+	Choices []Choice `json:"choices"`
+	// Usage is the token usage for the request.
+	Usage Usage `json:"usage"`
+}
+
+// Send posts a request to the chat completions endpoint under baseURL, with
+// the given extra headers, and returns the first choice as a completion
+// result.
+func Send(ctx context.Context, httpClient *http.Client, baseURL string, headers map[string]string, chatReq Request) (*provider.CompletionResult, error) {
+	body, err := json.Marshal(chatReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", baseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	start := time.Now()
+	resp, err := httpClient.Do(req)
+	latency := time.Since(start)
+
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(respBody))
+	}
+
+	var chatResp Response
+	if err := json.Unmarshal(respBody, &chatResp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	if len(chatResp.Choices) == 0 {
+		return nil, fmt.Errorf("no choices in response")
+	}
+
+	return &provider.CompletionResult{
+		Response:  json.RawMessage(chatResp.Choices[0].Message.Content),
+		Provider:  chatResp.Provider,
+		TokensIn:  chatResp.Usage.PromptTokens,
+		TokensOut: chatResp.Usage.CompletionTokens,
+		Latency:   latency,
+		Cost:      chatResp.Usage.Cost,
+	}, nil
+}
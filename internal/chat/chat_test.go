@@ -0,0 +1,51 @@
+package chat
+
+import (
+	"encoding/json"
+	"testing"
+
+	"go.carr.sh/litmus/internal/types"
+)
+
+func TestNewRequestParams(t *testing.T) {
+	temperature, topP, penalty := 0.2, 0.9, 0.5
+	seed, maxTokens := 7, 256
+
+	tests := []struct {
+		name   string
+		params types.Params
+		want   string
+	}{
+		{
+			name:   "provider defaults",
+			params: types.Params{},
+			want:   `{"model":"gpt-test","messages":[{"role":"system","content":"Extract."},{"role":"user","content":"Ada"}]}`,
+		},
+		{
+			name: "every parameter",
+			params: types.Params{
+				Temperature:      &temperature,
+				TopP:             &topP,
+				Seed:             &seed,
+				MaxTokens:        &maxTokens,
+				Stop:             []string{"END"},
+				FrequencyPenalty: &penalty,
+				PresencePenalty:  &penalty,
+			},
+			want: `{"model":"gpt-test","messages":[{"role":"system","content":"Extract."},{"role":"user","content":"Ada"}],` +
+				`"temperature":0.2,"top_p":0.9,"seed":7,"max_tokens":256,"stop":["END"],"frequency_penalty":0.5,"presence_penalty":0.5}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(NewRequest("gpt-test", "Extract.", "Ada", tt.params))
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("NewRequest() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+
+	"go.carr.sh/litmus/internal/compare"
+	"go.carr.sh/litmus/internal/config"
+)
+
+// compareOptions returns the comparison options from the config file, with
+// any tolerances and field handling set by flags overriding them. Fixing the
+// schema turns on null-as-absent unless it is set by its flag, as optional
+// fields rewritten as nullable come back as null rather than missing.
+func compareOptions(cmd *cobra.Command, cfg *config.Config) compare.Options {
+	var opts compare.Options
+	if cfg != nil {
+		opts = cfg.Compare
+	}
+
+	flags := cmd.Flags()
+	if flags.Changed("abs-tolerance") {
+		opts.Tolerance.Abs = absTolerance
+	}
+	if flags.Changed("rel-tolerance") {
+		opts.Tolerance.Rel = relTolerance
+	}
+	if flags.Changed("ignore-unexpected") {
+		opts.IgnoreUnexpected = ignoreUnexpected
+	}
+	if flags.Changed("null-as-absent") {
+		opts.NullAsAbsent = nullAsAbsent
+	} else if fixSchema {
+		opts.NullAsAbsent = true
+	}
+	return opts
+}
@@ -2,5 +2,22 @@ package cli
 
 import "errors"
 
+// Exit codes returned by the CLI.
+const (
+	// ExitFailed means tests ran but failed, a quality gate was breached, or
+	// validation found errors.
+	ExitFailed = 1
+	// ExitError means the run could not be completed, e.g. because of
+	// invalid flags or unreadable files.
+	ExitError = 2
+)
+
 // ErrTestsFailed is returned when one or more tests fail or error.
 var ErrTestsFailed = errors.New("one or more tests failed")
+
+// ErrGateBreached is returned when a model breaches a quality gate.
+var ErrGateBreached = errors.New("one or more quality gates were breached")
+
+// ErrValidationFailed is returned when validate finds errors in the test or
+// schema file.
+var ErrValidationFailed = errors.New("validation failed")
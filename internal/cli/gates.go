@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+
+	"go.carr.sh/litmus/internal/config"
+	"go.carr.sh/litmus/internal/types"
+)
+
+// flagGates returns the quality gates set by command-line flags.
+func flagGates(cmd *cobra.Command) types.Gates {
+	var g types.Gates
+	flags := cmd.Flags()
+	if flags.Changed("min-accuracy") {
+		g.MinAccuracy = &minAccuracy
+	}
+	if flags.Changed("max-p95-latency") {
+		d := types.Duration(maxP95Latency)
+		g.MaxP95Latency = &d
+	}
+	if flags.Changed("max-errors") {
+		g.MaxErrors = &maxErrors
+	}
+	if flags.Changed("max-cost") {
+		g.MaxCost = &maxCost
+	}
+	return g
+}
+
+// effectiveGates combines gates from the config file, flags and the config
+// model gates, in increasing order of precedence.
+func effectiveGates(cfg *config.Config, model string, fromFlags types.Gates) types.Gates {
+	var g types.Gates
+	if cfg != nil {
+		g = cfg.Gates
+	}
+	g = g.Merge(fromFlags)
+	if cfg != nil {
+		if mg, ok := cfg.ModelGates[model]; ok {
+			g = g.Merge(mg)
+		}
+	}
+	return g
+}
+
+// anyGates reports whether quality gates are set for any model.
+func anyGates(specs []modelSpec) bool {
+	for _, spec := range specs {
+		if !spec.Gates.IsZero() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,156 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"go.carr.sh/litmus/internal/config"
+	"go.carr.sh/litmus/internal/types"
+)
+
+// modelSpec is a model to run along with its effective sampling parameters.
+type modelSpec struct {
+	// Model is the model name, including any provider prefix.
+	Model string
+	// Params are the effective sampling parameters for the model.
+	Params types.Params
+	// Gates are the effective quality gates for the model.
+	Gates types.Gates
+}
+
+// parseModels parses the --model flags into model specs, resolving the
+// effective sampling parameters of each.
+func parseModels(cmd *cobra.Command, cfg *config.Config) ([]modelSpec, error) {
+	fromFlags := flagParams(cmd)
+	gatesFromFlags := flagGates(cmd)
+
+	specs := make([]modelSpec, 0, len(models))
+	for _, m := range models {
+		model, fromSpec, err := parseModelSpec(m)
+		if err != nil {
+			return nil, err
+		}
+		if model == "" {
+			continue
+		}
+		specs = append(specs, modelSpec{
+			Model:  model,
+			Params: effectiveParams(cfg, model, fromFlags, fromSpec),
+			Gates:  effectiveGates(cfg, model, gatesFromFlags),
+		})
+	}
+	return specs, nil
+}
+
+// flagParams returns the sampling parameters set by command-line flags.
+// Only flags that were explicitly set are included, so provider defaults
+// apply otherwise.
+func flagParams(cmd *cobra.Command) types.Params {
+	var p types.Params
+	flags := cmd.Flags()
+	if flags.Changed("temperature") {
+		p.Temperature = &temperature
+	}
+	if flags.Changed("top-p") {
+		p.TopP = &topP
+	}
+	if flags.Changed("seed") {
+		p.Seed = &seed
+	}
+	if flags.Changed("max-tokens") {
+		p.MaxTokens = &maxTokens
+	}
+	if flags.Changed("stop") {
+		p.Stop = stop
+	}
+	if flags.Changed("frequency-penalty") {
+		p.FrequencyPenalty = &frequencyPenalty
+	}
+	if flags.Changed("presence-penalty") {
+		p.PresencePenalty = &presencePenalty
+	}
+	return p
+}
+
+// parseModelSpec splits a --model value such as
+// "openai/gpt-4o@temperature=0,seed=1" into the model name and its
+// per-model parameter overrides.
+func parseModelSpec(spec string) (string, types.Params, error) {
+	var p types.Params
+
+	model, overrides, ok := strings.Cut(spec, "@")
+	model = strings.TrimSpace(model)
+	if !ok {
+		return model, p, nil
+	}
+
+	for _, pair := range strings.Split(overrides, ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return "", p, fmt.Errorf("model %s: invalid parameter %q: expected name=value", model, pair)
+		}
+		if err := setParam(&p, strings.TrimSpace(key), strings.TrimSpace(value)); err != nil {
+			return "", p, fmt.Errorf("model %s: %w", model, err)
+		}
+	}
+
+	return model, p, nil
+}
+
+// setParam sets a single named parameter from its string value.
+func setParam(p *types.Params, key, value string) error {
+	parseFloat := func() (*float64, error) {
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %w", key, value, err)
+		}
+		return &f, nil
+	}
+	parseInt := func() (*int, error) {
+		i, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %w", key, value, err)
+		}
+		return &i, nil
+	}
+
+	var err error
+	switch key {
+	case "temperature":
+		p.Temperature, err = parseFloat()
+	case "top_p", "top-p":
+		p.TopP, err = parseFloat()
+	case "seed":
+		p.Seed, err = parseInt()
+	case "max_tokens", "max-tokens":
+		p.MaxTokens, err = parseInt()
+	case "stop":
+		p.Stop = append(p.Stop, value)
+	case "frequency_penalty", "frequency-penalty":
+		p.FrequencyPenalty, err = parseFloat()
+	case "presence_penalty", "presence-penalty":
+		p.PresencePenalty, err = parseFloat()
+	default:
+		err = fmt.Errorf("unknown parameter %q", key)
+	}
+	return err
+}
+
+// effectiveParams combines parameters from the config file, flags and the
+// model spec, in increasing order of precedence.
+func effectiveParams(cfg *config.Config, model string, fromFlags, fromSpec types.Params) types.Params {
+	var p types.Params
+	if cfg != nil {
+		p = cfg.Params
+	}
+	p = p.Merge(fromFlags)
+	if cfg != nil {
+		if mp, ok := cfg.ModelParams[model]; ok {
+			p = p.Merge(mp)
+		}
+	}
+	return p.Merge(fromSpec)
+}
@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"testing"
+
+	"go.carr.sh/litmus/internal/config"
+	"go.carr.sh/litmus/internal/types"
+)
+
+func TestParseModelSpec(t *testing.T) {
+	tests := []struct {
+		spec      string
+		wantModel string
+		// wantParams is the String form of the overrides.
+		wantParams string
+		wantErr    bool
+	}{
+		{spec: "openai/gpt-4o", wantModel: "openai/gpt-4o"},
+		{spec: " openai/gpt-4o ", wantModel: "openai/gpt-4o"},
+		{spec: "openai/gpt-4o@temperature=0", wantModel: "openai/gpt-4o", wantParams: "temperature=0"},
+		{spec: "ollama:llama3.2@temperature=0.2, seed=7 ,max_tokens=256", wantModel: "ollama:llama3.2", wantParams: "temperature=0.2 seed=7 max_tokens=256"},
+		{spec: "openai/gpt-4o@top-p=0.9,frequency-penalty=0.5,presence_penalty=-1", wantModel: "openai/gpt-4o", wantParams: "top_p=0.9 frequency_penalty=0.5 presence_penalty=-1"},
+		{spec: `openai/gpt-4o@stop=END,stop=###`, wantModel: "openai/gpt-4o", wantParams: `stop=["END" "###"]`},
+		{spec: "openai/gpt-4o@temperature", wantErr: true},
+		{spec: "openai/gpt-4o@", wantErr: true},
+		{spec: "openai/gpt-4o@temperature=hot", wantErr: true},
+		{spec: "openai/gpt-4o@seed=1.5", wantErr: true},
+		{spec: "openai/gpt-4o@verbosity=2", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			model, params, err := parseModelSpec(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseModelSpec() = %q, %s, want an error", model, params)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseModelSpec() error = %v", err)
+			}
+			if model != tt.wantModel {
+				t.Errorf("model = %q, want %q", model, tt.wantModel)
+			}
+			if got := params.String(); got != tt.wantParams {
+				t.Errorf("params = %q, want %q", got, tt.wantParams)
+			}
+		})
+	}
+}
+
+func TestEffectiveParams(t *testing.T) {
+	float := func(f float64) *float64 { return &f }
+	integer := func(i int) *int { return &i }
+
+	cfg := &config.Config{
+		Params: types.Params{Temperature: float(1), TopP: float(0.9), Seed: integer(1), MaxTokens: integer(100)},
+		ModelParams: map[string]types.Params{
+			"openai/gpt-4o": {Seed: integer(3), MaxTokens: integer(300)},
+		},
+	}
+	fromFlags := types.Params{TopP: float(0.5), Seed: integer(2), MaxTokens: integer(200)}
+	fromSpec := types.Params{MaxTokens: integer(400)}
+
+	tests := []struct {
+		name      string
+		cfg       *config.Config
+		model     string
+		fromFlags types.Params
+		fromSpec  types.Params
+		want      string
+	}{
+		{
+			name: "nothing set",
+			want: "",
+		},
+		{
+			name:  "config only",
+			cfg:   cfg,
+			model: "anthropic:claude",
+			want:  "temperature=1 top_p=0.9 seed=1 max_tokens=100",
+		},
+		{
+			name:      "flags override config",
+			cfg:       cfg,
+			model:     "anthropic:claude",
+			fromFlags: fromFlags,
+			want:      "temperature=1 top_p=0.5 seed=2 max_tokens=200",
+		},
+		{
+			name:      "model params override flags",
+			cfg:       cfg,
+			model:     "openai/gpt-4o",
+			fromFlags: fromFlags,
+			want:      "temperature=1 top_p=0.5 seed=3 max_tokens=300",
+		},
+		{
+			name:      "spec overrides model params",
+			cfg:       cfg,
+			model:     "openai/gpt-4o",
+			fromFlags: fromFlags,
+			fromSpec:  fromSpec,
+			want:      "temperature=1 top_p=0.5 seed=3 max_tokens=400",
+		},
+		{
+			name:      "without a config file",
+			model:     "openai/gpt-4o",
+			fromFlags: fromFlags,
+			fromSpec:  fromSpec,
+			want:      "top_p=0.5 seed=2 max_tokens=400",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := effectiveParams(tt.cfg, tt.model, tt.fromFlags, tt.fromSpec).String(); got != tt.want {
+				t.Errorf("effectiveParams() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
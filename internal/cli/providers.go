@@ -0,0 +1,297 @@
+package cli
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.carr.sh/litmus/internal/anthropic"
+	"go.carr.sh/litmus/internal/cache"
+	"go.carr.sh/litmus/internal/cassette"
+	"go.carr.sh/litmus/internal/config"
+	"go.carr.sh/litmus/internal/gemini"
+	"go.carr.sh/litmus/internal/jsonschema"
+	"go.carr.sh/litmus/internal/ollama"
+	"go.carr.sh/litmus/internal/openai"
+	"go.carr.sh/litmus/internal/openrouter"
+	"go.carr.sh/litmus/internal/provider"
+	"go.carr.sh/litmus/internal/util"
+)
+
+// newProvider builds the provider used for a run, and returns the registry
+// of backends so that callers can tell which backend serves a model. When
+// replaying, responses come from the cassette directory and no backend is
+// contacted. Otherwise the registry is used, and backends for every requested
+// model are resolved up front, along with any judge models, so missing
+// credentials fail before any requests are sent. Recording bypasses the
+// response cache so cassettes only contain live responses.
+func newProvider(cfg *config.Config, specs []modelSpec) (provider.Provider, *provider.Registry, error) {
+	registry, err := newRegistry(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if replayDir != "" {
+		player, err := cassette.NewPlayer(replayDir)
+		return player, registry, err
+	}
+
+	for _, spec := range specs {
+		if _, _, err := registry.Resolve(spec.Model); err != nil {
+			return nil, nil, err
+		}
+	}
+	if cfg != nil {
+		for _, model := range cfg.Compare.JudgeModels() {
+			if _, _, err := registry.Resolve(model); err != nil {
+				return nil, nil, fmt.Errorf("judge model %s: %w", model, err)
+			}
+		}
+	}
+
+	if recordDir != "" {
+		recorder, err := cassette.NewRecorder(recordDir, registry)
+		return recorder, registry, err
+	}
+
+	if noCache {
+		return registry, registry, nil
+	}
+
+	dir := cacheDir
+	if dir == "" {
+		dir, err = cache.DefaultDir()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to locate cache directory (use --cache-dir or --no-cache): %w", err)
+		}
+	}
+	c, err := cache.New(dir, cacheTTL, registry)
+	return c, registry, err
+}
+
+// newRegistry creates a provider registry with the built-in backends and any
+// backends declared in cfg. Models without a provider prefix are routed to
+// OpenRouter.
+func newRegistry(cfg *config.Config) (*provider.Registry, error) {
+	reg := provider.NewRegistry("openrouter")
+
+	reg.Register("openrouter", func() (provider.Provider, error) {
+		key := apiKey
+		if key == "" {
+			key = os.Getenv("OPENROUTER_API_KEY")
+		}
+		if key == "" {
+			return nil, fmt.Errorf("API key required: use --api-key or set OPENROUTER_API_KEY environment variable")
+		}
+		return openrouter.NewClient(key), nil
+	})
+
+	headers, err := parseHeaders(openaiHeaders)
+	if err != nil {
+		return nil, err
+	}
+	baseURL := openaiBaseURL
+	if baseURL == "" {
+		baseURL = os.Getenv("OPENAI_BASE_URL")
+	}
+	reg.Register("openai", newOpenAIFactory(config.ProviderConfig{
+		Type:           config.ProviderOpenAI,
+		BaseURL:        baseURL,
+		APIKeyEnv:      "OPENAI_API_KEY",
+		Headers:        headers,
+		ResponseFormat: openaiResponseFormat,
+	}))
+
+	reg.Register("anthropic", newAnthropicFactory(config.ProviderConfig{Type: config.ProviderAnthropic}))
+
+	reg.Register("gemini", newGeminiFactory(config.ProviderConfig{Type: config.ProviderGemini}))
+
+	reg.Register("ollama", newOllamaFactory(config.ProviderConfig{
+		Type:    config.ProviderOllama,
+		BaseURL: os.Getenv("OLLAMA_HOST"),
+	}))
+
+	if cfg != nil {
+		for name, pc := range cfg.Providers {
+			switch pc.Type {
+			case config.ProviderOpenAI:
+				reg.Register(name, newOpenAIFactory(pc))
+			case config.ProviderAnthropic:
+				reg.Register(name, newAnthropicFactory(pc))
+			case config.ProviderGemini:
+				reg.Register(name, newGeminiFactory(pc))
+			case config.ProviderOllama:
+				reg.Register(name, newOllamaFactory(pc))
+			default:
+				return nil, fmt.Errorf("provider %s: unknown type %q", name, pc.Type)
+			}
+		}
+	}
+
+	return reg, nil
+}
+
+// newOpenAIFactory returns a factory for an OpenAI-compatible backend.
+func newOpenAIFactory(pc config.ProviderConfig) provider.Factory {
+	return func() (provider.Provider, error) {
+		var opts []openai.Option
+		if pc.BaseURL != "" {
+			opts = append(opts, openai.WithBaseURL(pc.BaseURL))
+		}
+		if len(pc.Headers) > 0 {
+			opts = append(opts, openai.WithHeaders(pc.Headers))
+		}
+		if pc.ResponseFormat != "" {
+			if !openai.ValidResponseFormat(pc.ResponseFormat) {
+				return nil, fmt.Errorf("unknown response format: %s (valid: %s)",
+					pc.ResponseFormat, strings.Join(openai.ResponseFormats, ", "))
+			}
+			opts = append(opts, openai.WithResponseFormat(pc.ResponseFormat))
+		}
+
+		var key string
+		if pc.APIKeyEnv != "" {
+			key = os.Getenv(pc.APIKeyEnv)
+		}
+		return openai.NewClient(key, opts...), nil
+	}
+}
+
+// newAnthropicFactory returns a factory for an Anthropic Messages API backend.
+func newAnthropicFactory(pc config.ProviderConfig) provider.Factory {
+	return func() (provider.Provider, error) {
+		var opts []anthropic.Option
+		if pc.BaseURL != "" {
+			opts = append(opts, anthropic.WithBaseURL(pc.BaseURL))
+		}
+		if len(pc.Headers) > 0 {
+			opts = append(opts, anthropic.WithHeaders(pc.Headers))
+		}
+
+		keyEnv := cmp.Or(pc.APIKeyEnv, "ANTHROPIC_API_KEY")
+		key := os.Getenv(keyEnv)
+		if key == "" {
+			return nil, fmt.Errorf("API key required: set %s environment variable", keyEnv)
+		}
+		return anthropic.NewClient(key, opts...), nil
+	}
+}
+
+// newGeminiFactory returns a factory for a Google Gemini API backend.
+func newGeminiFactory(pc config.ProviderConfig) provider.Factory {
+	return func() (provider.Provider, error) {
+		var opts []gemini.Option
+		if pc.BaseURL != "" {
+			opts = append(opts, gemini.WithBaseURL(pc.BaseURL))
+		}
+		if len(pc.Headers) > 0 {
+			opts = append(opts, gemini.WithHeaders(pc.Headers))
+		}
+
+		keyEnv := cmp.Or(pc.APIKeyEnv, "GEMINI_API_KEY")
+		key := os.Getenv(keyEnv)
+		if key == "" {
+			return nil, fmt.Errorf("API key required: set %s environment variable", keyEnv)
+		}
+		return gemini.NewClient(key, opts...), nil
+	}
+}
+
+// newOllamaFactory returns a factory for a local Ollama backend.
+func newOllamaFactory(pc config.ProviderConfig) provider.Factory {
+	return func() (provider.Provider, error) {
+		var opts []ollama.Option
+		if pc.BaseURL != "" {
+			opts = append(opts, ollama.WithBaseURL(pc.BaseURL))
+		}
+		if len(pc.Headers) > 0 {
+			opts = append(opts, ollama.WithHeaders(pc.Headers))
+		}
+		return ollama.NewClient(opts...), nil
+	}
+}
+
+// parseHeaders parses "Name: Value" header flags into a map.
+func parseHeaders(values []string) (map[string]string, error) {
+	headers := make(map[string]string, len(values))
+	for _, v := range values {
+		name, value, ok := strings.Cut(v, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid header %q: expected \"Name: Value\"", v)
+		}
+		headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return headers, nil
+}
+
+// modelDialect returns the schema dialect enforced by the backend serving a
+// model, if any. OpenRouter and OpenAI-compatible backends sending strict
+// "json_schema" response formats use OpenAI's dialect.
+func modelDialect(reg *provider.Registry, cfg *config.Config, model string) (jsonschema.Dialect, bool) {
+	name, _ := reg.Split(model)
+
+	var pc config.ProviderConfig
+	declared := false
+	if cfg != nil {
+		pc, declared = cfg.Providers[name]
+	}
+
+	format := ""
+	switch {
+	case declared && pc.Type == config.ProviderOpenAI:
+		format = pc.ResponseFormat
+	case declared:
+		return jsonschema.Dialect{}, false
+	case name == "openrouter":
+		return jsonschema.OpenAI, true
+	case name == "openai":
+		format = openaiResponseFormat
+	default:
+		return jsonschema.Dialect{}, false
+	}
+
+	if format != "" && format != openai.FormatJSONSchema {
+		return jsonschema.Dialect{}, false
+	}
+	return jsonschema.OpenAI, true
+}
+
+// schemaFor returns the schema to send to a model, along with warnings about
+// any incompatibilities with the model's schema dialect. With --fix-schema
+// the schema is rewritten for the dialect, and the rewritten schema is also
+// returned if anything changed.
+func schemaFor(reg *provider.Registry, cfg *config.Config, model string, schema json.RawMessage) (json.RawMessage, json.RawMessage, []string, error) {
+	d, ok := modelDialect(reg, cfg, model)
+	if !ok {
+		return schema, nil, nil, nil
+	}
+
+	var warnings []string
+	var fixed json.RawMessage
+	if fixSchema {
+		rewritten, changes, err := jsonschema.Fix(schema, d)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if len(changes) > 0 {
+			schema, fixed = rewritten, rewritten
+			for _, change := range changes {
+				warnings = append(warnings, fmt.Sprintf("schema fixed for %s strict mode: %s", d.Name, change))
+			}
+		}
+	}
+
+	issues, err := jsonschema.Check(schema, d)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	for _, issue := range issues {
+		warnings = append(warnings, fmt.Sprintf("schema incompatible with %s strict mode: %s: %s", d.Name, util.PathOrRoot(issue.Pointer), issue.Message))
+	}
+	if len(issues) > 0 && !fixSchema {
+		warnings = append(warnings, "use --fix-schema to rewrite optional fields as nullable and drop unsupported keywords")
+	}
+	return schema, fixed, warnings, nil
+}
@@ -25,12 +25,25 @@ It allows you to:
 // Execute runs the root command.
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
-		// ErrTestsFailed means tests ran but some failed - results already printed
-		if errors.Is(err, ErrTestsFailed) {
-			os.Exit(1)
+		code := exitCode(err)
+		if code == ExitError {
+			fmt.Fprintln(os.Stderr, err)
 		}
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		os.Exit(code)
+	}
+}
+
+// exitCode returns the exit code for an error returned by a command.
+func exitCode(err error) int {
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, ErrTestsFailed), errors.Is(err, ErrGateBreached), errors.Is(err, ErrValidationFailed):
+		// Tests ran but failed, breached a gate or were invalid - results
+		// already printed
+		return ExitFailed
+	default:
+		return ExitError
 	}
 }
 
@@ -44,5 +57,6 @@ var versionCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(runCmd)
+	rootCmd.AddCommand(validateCmd)
 	rootCmd.AddCommand(versionCmd)
 }
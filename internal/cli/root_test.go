@@ -0,0 +1,192 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"go.carr.sh/litmus/internal/cassette"
+	"go.carr.sh/litmus/internal/provider"
+	"go.carr.sh/litmus/internal/runner"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"success", nil, 0},
+		{"tests failed", ErrTestsFailed, ExitFailed},
+		{"gate breached", ErrGateBreached, ExitFailed},
+		{"validation failed", fmt.Errorf("tests.json: %w", ErrValidationFailed), ExitFailed},
+		{"replay miss", fmt.Errorf("requests not recorded: %w", cassette.ErrMiss), ExitError},
+		{"bad flags", errors.New("--repeat must be at least 1"), ExitError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.err); got != tt.want {
+				t.Errorf("exitCode(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+// execute runs the CLI with args, returning what it wrote to stdout, or to
+// the command's output, and the error that decides the exit code. Flags are
+// reset first, as cobra keeps them between runs.
+func execute(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	for _, cmd := range rootCmd.Commands() {
+		cmd.Flags().VisitAll(func(f *pflag.Flag) {
+			if s, ok := f.Value.(pflag.SliceValue); ok {
+				f.Value = &resetSlice{Value: f.Value, slice: s}
+			}
+			if r, ok := f.Value.(*resetSlice); ok {
+				r.reset(f.DefValue)
+			} else {
+				f.Value.Set(f.DefValue)
+			}
+			f.Changed = false
+		})
+	}
+
+	out, err := os.CreateTemp(t.TempDir(), "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = out
+	defer func() { os.Stdout = stdout }()
+
+	rootCmd.SetArgs(args)
+	rootCmd.SetOut(out)
+	rootCmd.SetErr(io.Discard)
+	err = rootCmd.Execute()
+
+	out.Seek(0, io.SeekStart)
+	data, _ := io.ReadAll(out)
+	out.Close()
+	return string(data), err
+}
+
+// resetSlice wraps a slice flag so that it can be reset between runs. Slice
+// flags append to their values once set, so the first value given after a
+// reset replaces the default instead.
+type resetSlice struct {
+	pflag.Value
+	// slice is the wrapped flag value.
+	slice pflag.SliceValue
+	// set is true once a value has been given since the last reset.
+	set bool
+}
+
+// reset restores the default value, given in its "[a,b]" form.
+func (r *resetSlice) reset(def string) {
+	var values []string
+	if d := strings.Trim(def, "[]"); d != "" {
+		values = strings.Split(d, ",")
+	}
+	r.slice.Replace(values)
+	r.set = false
+}
+
+func (r *resetSlice) Set(value string) error {
+	if !r.set {
+		r.set = true
+		r.slice.Replace(nil)
+	}
+	return r.Value.Set(value)
+}
+
+// writeFile writes content to name in dir and returns its path.
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// stubProvider answers each request with the response for its input.
+type stubProvider struct {
+	responses map[string]string
+	latency   time.Duration
+}
+
+func (s stubProvider) Complete(ctx context.Context, req provider.Request) (*provider.CompletionResult, error) {
+	response, ok := s.responses[req.UserInput]
+	if !ok {
+		return nil, fmt.Errorf("no response for %q", req.UserInput)
+	}
+	cost := 0.0
+	return &provider.CompletionResult{Response: json.RawMessage(response), Latency: s.latency, Cost: &cost}, nil
+}
+
+// record writes a cassette with the responses of model to each test input.
+func record(t *testing.T, dir, model, prompt, schemaPath string, p stubProvider) {
+	t.Helper()
+	schema, err := runner.LoadSchema(schemaPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder, err := cassette.NewRecorder(dir, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for input := range p.responses {
+		req := provider.Request{Model: model, SystemPrompt: prompt, UserInput: input, Schema: schema}
+		if _, err := recorder.Complete(context.Background(), req); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRunExitCodes(t *testing.T) {
+	dir := t.TempDir()
+	schema := writeFile(t, dir, "schema.json", `{"type":"object","properties":{"n":{"type":"integer"}}}`)
+	tests := writeFile(t, dir, "tests.json", `[
+  {"name": "one", "input": "one", "expected": {"n": 1}},
+  {"name": "two", "input": "two", "expected": {"n": 2}}
+]`)
+	cassettes := filepath.Join(dir, "cassettes")
+	// The second test fails, for an accuracy of 50%
+	record(t, cassettes, "ollama:llama3.2", "Count.", schema, stubProvider{
+		responses: map[string]string{"one": `{"n":1}`, "two": `{"n":3}`},
+		latency:   time.Second,
+	})
+	base := []string{"run", "--tests", tests, "--schema", schema, "--prompt", "Count.", "--output", "json", "--replay", cassettes}
+
+	cases := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"failed test", []string{"--model", "ollama:llama3.2"}, ExitFailed},
+		{"gates met", []string{"--model", "ollama:llama3.2", "--min-accuracy", "50", "--max-p95-latency", "2s"}, 0},
+		{"accuracy gate breached", []string{"--model", "ollama:llama3.2", "--min-accuracy", "75"}, ExitFailed},
+		{"latency gate breached", []string{"--model", "ollama:llama3.2", "--max-p95-latency", "500ms"}, ExitFailed},
+		{"unrecorded model", []string{"--model", "ollama:llama3.2", "--model", "ollama:qwen3"}, ExitError},
+		{"invalid flags", []string{"--model", "ollama:llama3.2", "--repeat", "0"}, ExitError},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := execute(t, append(base, tt.args...)...)
+			if got := exitCode(err); got != tt.want {
+				t.Errorf("exit code = %d (%v), want %d", got, err, tt.want)
+			}
+		})
+	}
+}
@@ -2,15 +2,19 @@ package cli
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
-	"strings"
 	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
 
+	"go.carr.sh/litmus/internal/cassette"
+	"go.carr.sh/litmus/internal/config"
+	"go.carr.sh/litmus/internal/openai"
 	"go.carr.sh/litmus/internal/reporter"
 	"go.carr.sh/litmus/internal/runner"
 	"go.carr.sh/litmus/internal/types"
@@ -24,15 +28,51 @@ var (
 	promptFile   string
 	models       []string
 	parallel     int
+	repeat       int
 	outputFormat string
 	jsonOutput   bool // Deprecated: use --output=json instead
 	apiKey       string
+	configFile   string
+	recordDir    string
+	replayDir    string
+	cacheDir     string
+	noCache      bool
+	cacheTTL     time.Duration
+
+	temperature      float64
+	topP             float64
+	seed             int
+	maxTokens        int
+	stop             []string
+	frequencyPenalty float64
+	presencePenalty  float64
+
+	minAccuracy   float64
+	maxP95Latency time.Duration
+	maxErrors     int
+	maxCost       float64
+
+	absTolerance float64
+	relTolerance float64
+
+	ignoreUnexpected bool
+	nullAsAbsent     bool
+
+	fixSchema bool
+
+	openaiBaseURL        string
+	openaiResponseFormat string
+	openaiHeaders        []string
 )
 
 var runCmd = &cobra.Command{
 	Use:   "run",
 	Short: "Run tests against LLM models",
-	Long: `Run specification tests against one or more LLM models via OpenRouter.
+	Long: `Run specification tests against one or more LLM models.
+
+Models are routed to a backend by an optional prefix: "openrouter:",
+"openai:" (any OpenAI-compatible server), "anthropic:", "gemini:" or
+"ollama:", plus any providers declared in the --config file. Models without a prefix are sent to OpenRouter.
 
 Examples:
   # Basic usage
@@ -42,6 +82,10 @@ Examples:
   litmus run --tests tests.json --schema schema.json --prompt "Extract entities" \
     --model openai/gpt-4o --model anthropic/claude-3.5-sonnet
 
+  # Sampling parameters, with a per-model override
+  litmus run --tests tests.json --schema schema.json --prompt-file prompt.txt \
+    --temperature 0.2 --seed 42 --model openai/gpt-4o --model "anthropic/claude-3.5-sonnet@temperature=0"
+
   # JSON output for CI/CD
   litmus run --tests tests.json --schema schema.json --prompt-file prompt.txt \
     --model openai/gpt-4o --output=json
@@ -50,6 +94,32 @@ Examples:
   litmus run --tests tests.json --schema schema.json --prompt-file prompt.txt \
     --model openai/gpt-4o --output=html > report.html
 
+  # Self-hosted OpenAI-compatible server (e.g. vLLM)
+  litmus run --tests tests.json --schema schema.json --prompt-file prompt.txt \
+    --model openai:meta-llama/Llama-3.1-8B-Instruct --openai-base-url http://localhost:8000/v1
+
+  # Local model via Ollama (no API key required)
+  litmus run --tests tests.json --schema schema.json --prompt-file prompt.txt \
+    --model ollama:llama3.2:3b
+
+  # Record responses once, then re-score offline
+  litmus run --tests tests.json --schema schema.json --prompt-file prompt.txt \
+    --model openai/gpt-4o --record cassettes/
+  litmus run --tests tests.json --schema schema.json --prompt-file prompt.txt \
+    --model openai/gpt-4o --replay cassettes/
+
+  # Run each test 5 times to measure pass@k and consistency
+  litmus run --tests tests.json --schema schema.json --prompt-file prompt.txt \
+    --model openai/gpt-4o --temperature 0.7 --repeat 5
+
+  # CI gate: tolerate a few failures, but not errors or slow responses
+  litmus run --tests tests.json --schema schema.json --prompt-file prompt.txt \
+    --model openai/gpt-4o --min-accuracy 95 --max-errors 0 --max-p95-latency 2s
+
+  # Rewrite a schema with optional fields for OpenAI's strict mode
+  litmus run --tests tests.json --schema schema.json --prompt-file prompt.txt \
+    --model openai/gpt-4o --fix-schema
+
   # Parallel execution
   litmus run --tests tests.json --schema schema.json --prompt-file prompt.txt \
     --model openai/gpt-4o --parallel 5`,
@@ -57,16 +127,45 @@ Examples:
 }
 
 func init() {
-	runCmd.Flags().StringVarP(&testsFile, "tests", "t", "", "Path to test cases JSON file (required)")
+	runCmd.Flags().StringVarP(&testsFile, "tests", "t", "", "Path to test cases file: .json, .jsonl, .yaml, .csv or .tsv (required)")
 	runCmd.Flags().StringVarP(&schemaFile, "schema", "s", "", "Path to JSON schema file (required)")
 	runCmd.Flags().StringVarP(&prompt, "prompt", "p", "", "System prompt for the LLM")
 	runCmd.Flags().StringVar(&promptFile, "prompt-file", "", "Path to file containing system prompt")
 	runCmd.Flags().StringArrayVarP(&models, "model", "m", nil, "Model(s) to test against (required, can be repeated)")
 	runCmd.Flags().IntVarP(&parallel, "parallel", "P", 1, "Number of parallel requests per model")
+	runCmd.Flags().IntVarP(&repeat, "repeat", "n", 1, "Number of times to run each test, to measure consistency")
 	runCmd.Flags().StringVarP(&outputFormat, "output", "o", "terminal", "Output format: terminal, json, html")
 	runCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output results as JSON (deprecated: use --output=json)")
 	runCmd.Flags().MarkDeprecated("json", "use --output=json instead")
 	runCmd.Flags().StringVar(&apiKey, "api-key", "", "OpenRouter API key (or use OPENROUTER_API_KEY env var)")
+	runCmd.Flags().StringVarP(&configFile, "config", "c", "", "Path to litmus config file")
+	runCmd.Flags().StringVar(&recordDir, "record", "", "Record every response to a cassette directory")
+	runCmd.Flags().StringVar(&replayDir, "replay", "", "Replay responses from a cassette directory instead of calling providers")
+	runCmd.Flags().StringVar(&cacheDir, "cache-dir", "", "Response cache directory (default: litmus/responses in the user cache directory)")
+	runCmd.Flags().BoolVar(&noCache, "no-cache", false, "Disable the response cache")
+	runCmd.Flags().DurationVar(&cacheTTL, "cache-ttl", 24*time.Hour, "How long cached responses remain valid (0 for no expiry)")
+	runCmd.Flags().StringVar(&openaiBaseURL, "openai-base-url", "", "Base URL for openai: models (or use OPENAI_BASE_URL env var)")
+	runCmd.Flags().StringVar(&openaiResponseFormat, "openai-response-format", openai.FormatJSONSchema, "Response format for openai: models: json_schema, json_object, none")
+	runCmd.Flags().StringArrayVar(&openaiHeaders, "openai-header", nil, "Extra HTTP header for openai: models as \"Name: Value\" (can be repeated)")
+
+	runCmd.Flags().Float64Var(&temperature, "temperature", 0, "Sampling temperature (default: provider default)")
+	runCmd.Flags().Float64Var(&topP, "top-p", 0, "Nucleus sampling probability mass (default: provider default)")
+	runCmd.Flags().IntVar(&seed, "seed", 0, "Sampling seed for reproducible outputs, where supported")
+	runCmd.Flags().IntVar(&maxTokens, "max-tokens", 0, "Maximum number of tokens to generate (default: provider default)")
+	runCmd.Flags().StringArrayVar(&stop, "stop", nil, "Stop sequence (can be repeated)")
+	runCmd.Flags().Float64Var(&frequencyPenalty, "frequency-penalty", 0, "Frequency penalty (default: provider default)")
+	runCmd.Flags().Float64Var(&presencePenalty, "presence-penalty", 0, "Presence penalty (default: provider default)")
+
+	runCmd.Flags().Float64Var(&minAccuracy, "min-accuracy", 0, "Gate: minimum accuracy percentage per model")
+	runCmd.Flags().DurationVar(&maxP95Latency, "max-p95-latency", 0, "Gate: maximum P95 latency per model")
+	runCmd.Flags().IntVar(&maxErrors, "max-errors", 0, "Gate: maximum number of errored tests per model")
+	runCmd.Flags().Float64Var(&maxCost, "max-cost", 0, "Gate: maximum total cost in USD per model")
+
+	runCmd.Flags().Float64Var(&absTolerance, "abs-tolerance", 0, "Absolute tolerance when comparing numbers")
+	runCmd.Flags().Float64Var(&relTolerance, "rel-tolerance", 0, "Relative tolerance when comparing numbers, e.g. 0.01 for 1%")
+	runCmd.Flags().BoolVar(&ignoreUnexpected, "ignore-unexpected", false, "Ignore response fields that are not in the expected value")
+	runCmd.Flags().BoolVar(&nullAsAbsent, "null-as-absent", false, "Treat fields set to null as absent")
+	runCmd.Flags().BoolVar(&fixSchema, "fix-schema", false, "Rewrite the schema for OpenAI's strict mode, used by openrouter: and openai: models, making optional fields nullable")
 
 	runCmd.MarkFlagRequired("tests")
 	runCmd.MarkFlagRequired("schema")
@@ -79,13 +178,28 @@ func runTests(cmd *cobra.Command, args []string) error {
 		outputFormat = "json"
 	}
 
-	// Get API key
-	key := apiKey
-	if key == "" {
-		key = os.Getenv("OPENROUTER_API_KEY")
+	// Load config
+	var cfg *config.Config
+	if configFile != "" {
+		var err error
+		cfg, err = config.Load(configFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	if recordDir != "" && replayDir != "" {
+		return fmt.Errorf("--record and --replay are mutually exclusive")
 	}
-	if key == "" {
-		return fmt.Errorf("API key required: use --api-key or set OPENROUTER_API_KEY environment variable")
+
+	specs, err := parseModels(cmd, cfg)
+	if err != nil {
+		return err
+	}
+
+	p, registry, err := newProvider(cfg, specs)
+	if err != nil {
+		return err
 	}
 
 	// Get prompt
@@ -105,8 +219,14 @@ func runTests(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("prompt required: use --prompt or --prompt-file")
 	}
 
+	// Load schema
+	schema, err := runner.LoadSchema(schemaFile)
+	if err != nil {
+		return err
+	}
+
 	// Load test file
-	tests, err := runner.LoadTestFile(testsFile)
+	tests, err := runner.LoadTestFile(testsFile, loadOptions(cfg, schema)...)
 	if err != nil {
 		return err
 	}
@@ -115,12 +235,6 @@ func runTests(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("no tests found in %s", testsFile)
 	}
 
-	// Load schema
-	schema, err := runner.LoadSchema(schemaFile)
-	if err != nil {
-		return err
-	}
-
 	// Setup context with cancellation
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -134,8 +248,15 @@ func runTests(cmd *cobra.Command, args []string) error {
 		cancel()
 	}()
 
+	if repeat < 1 {
+		return fmt.Errorf("--repeat must be at least 1")
+	}
+
 	// Create runner
-	r := runner.New(key, parallel)
+	r := runner.New(p, parallel,
+		runner.WithRepeat(repeat),
+		runner.WithCompareOptions(compareOptions(cmd, cfg)),
+	)
 
 	// Prepare report
 	report := &types.RunReport{
@@ -143,21 +264,37 @@ func runTests(cmd *cobra.Command, args []string) error {
 		Prompt:    util.Truncate(systemPrompt, 100),
 		Schema:    schemaFile,
 		TestFile:  testsFile,
-		Models:    make([]types.ModelRun, 0, len(models)),
+		Models:    make([]types.ModelRun, 0, len(specs)),
 	}
 
-	// Run tests for each model
-	for _, model := range models {
-		model = strings.TrimSpace(model)
-		if model == "" {
-			continue
+	// Run tests for each model. Replay misses abort the run, but only after
+	// every model has been tried so that all missing requests are named.
+	var misses []error
+	for _, spec := range specs {
+		if outputFormat == "terminal" {
+			if repeat > 1 {
+				fmt.Fprintf(os.Stderr, "Running %d tests x %d trials against %s...\n", len(tests), repeat, spec.Model)
+			} else {
+				fmt.Fprintf(os.Stderr, "Running %d tests against %s...\n", len(tests), spec.Model)
+			}
 		}
 
-		if outputFormat == "terminal" {
-			fmt.Fprintf(os.Stderr, "Running %d tests against %s...\n", len(tests), model)
+		modelSchema, fixed, warnings, err := schemaFor(registry, cfg, spec.Model, schema)
+		if err != nil {
+			return err
 		}
 
-		modelRun := r.Run(ctx, model, systemPrompt, schema, tests)
+		modelRun, err := r.Run(ctx, spec.Model, spec.Params, systemPrompt, modelSchema, tests)
+		if errors.Is(err, cassette.ErrMiss) {
+			misses = append(misses, err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		modelRun.FixedSchema = fixed
+		modelRun.Warnings = append(warnings, modelRun.Warnings...)
+		modelRun.Gates = spec.Gates.Evaluate(modelRun.Metrics)
 		report.Models = append(report.Models, *modelRun)
 
 		// Check for context cancellation
@@ -166,6 +303,21 @@ func runTests(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if len(misses) > 0 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("requests not recorded in %s:\n%w", replayDir, errors.Join(misses...))
+	}
+
+	if anyGates(specs) {
+		passed := true
+		for _, mr := range report.Models {
+			for _, g := range mr.Gates {
+				passed = passed && g.Passed
+			}
+		}
+		report.GatesPassed = &passed
+	}
+
 	// Output results
 	var rep reporter.Reporter
 	switch outputFormat {
@@ -183,13 +335,36 @@ func runTests(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	// Return error if any tests failed
+	cmd.SilenceUsage = true
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	// With gates, the run fails only if a gate is breached; otherwise any
+	// failed, invalid or errored test fails it
+	if report.GatesPassed != nil {
+		if !*report.GatesPassed {
+			return ErrGateBreached
+		}
+		return nil
+	}
+
 	for _, mr := range report.Models {
-		if mr.Metrics.Failed > 0 || mr.Metrics.Errors > 0 {
-			cmd.SilenceUsage = true
+		if mr.Metrics.Failed > 0 || mr.Metrics.Errors > 0 || mr.Metrics.Invalid > 0 {
 			return ErrTestsFailed
 		}
 	}
 
 	return nil
 }
+
+// loadOptions returns the options for loading the test file: the column
+// mapping from the config file and the schema used to coerce table cells.
+func loadOptions(cfg *config.Config, schema json.RawMessage) []runner.LoadOption {
+	opts := []runner.LoadOption{runner.WithSchema(schema)}
+	if cfg != nil {
+		opts = append(opts, runner.WithColumns(cfg.Columns))
+	}
+	return opts
+}
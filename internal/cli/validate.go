@@ -0,0 +1,366 @@
+package cli
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"go.carr.sh/litmus/internal/compare"
+	"go.carr.sh/litmus/internal/config"
+	"go.carr.sh/litmus/internal/jsonschema"
+	"go.carr.sh/litmus/internal/runner"
+	"go.carr.sh/litmus/internal/types"
+	"go.carr.sh/litmus/internal/util"
+)
+
+// Flags of the validate command.
+var (
+	validateTestsFile  string
+	validateSchemaFile string
+	validateConfigFile string
+	// dialects are the provider schema dialects that validate checks.
+	dialects []string
+)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check test and schema files for mistakes",
+	Long: `Check a test file and schema for mistakes without calling any models.
+
+Errors are reported for expected values that violate the schema, duplicate
+test names and empty inputs. Warnings are reported for schema features that
+a provider dialect rejects, such as objects with optional properties or
+without "additionalProperties": false in OpenAI's strict mode. OpenAI's
+dialect, also used by OpenRouter, is the only one checked; schemas for
+Anthropic and Gemini models are not.
+
+Examples:
+  litmus validate --tests tests.json --schema schema.json
+
+  # Skip the provider dialect checks
+  litmus validate --tests tests.json --schema schema.json --dialect ""`,
+	RunE: validateFiles,
+}
+
+func init() {
+	validateCmd.Flags().StringVarP(&validateTestsFile, "tests", "t", "", "Path to test cases file: .json, .jsonl, .yaml, .csv or .tsv (required)")
+	validateCmd.Flags().StringVarP(&validateSchemaFile, "schema", "s", "", "Path to JSON schema file (required)")
+	validateCmd.Flags().StringVarP(&validateConfigFile, "config", "c", "", "Path to litmus config file")
+	validateCmd.Flags().StringSliceVar(&dialects, "dialect", []string{jsonschema.OpenAI.Name}, "Provider schema dialect(s) to check the schema against; only \"openai\" is supported")
+
+	validateCmd.MarkFlagRequired("tests")
+	validateCmd.MarkFlagRequired("schema")
+}
+
+// Severities of a problem found by validate.
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+)
+
+// problem is a mistake found in a test or schema file.
+type problem struct {
+	// file is the path of the file containing the problem.
+	file string
+	// line is the line of the problem, or 0 if it is not known.
+	line int
+	// severity is severityError or severityWarning.
+	severity string
+	// message describes the problem.
+	message string
+}
+
+// validation collects the problems found in a set of files.
+type validation struct {
+	// problems are the problems found so far.
+	problems []problem
+}
+
+// add records a problem.
+func (v *validation) add(file string, line int, severity, format string, args ...any) {
+	v.problems = append(v.problems, problem{
+		file:     file,
+		line:     line,
+		severity: severity,
+		message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// addLoadError records a file that could not be loaded, at the line of the
+// parse error where known.
+func (v *validation) addLoadError(file string, err error) {
+	var parseErr *runner.ParseError
+	if errors.As(err, &parseErr) {
+		v.add(file, parseErr.Line, severityError, "%v", parseErr.Err)
+		return
+	}
+	v.add(file, 0, severityError, "%v", err)
+}
+
+// count returns the number of problems with the given severity.
+func (v *validation) count(severity string) int {
+	n := 0
+	for _, p := range v.problems {
+		if p.severity == severity {
+			n++
+		}
+	}
+	return n
+}
+
+func validateFiles(cmd *cobra.Command, args []string) error {
+	var cfg *config.Config
+	if validateConfigFile != "" {
+		var err error
+		cfg, err = config.Load(validateConfigFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	testData, err := os.ReadFile(validateTestsFile)
+	if err != nil {
+		return fmt.Errorf("failed to read test file: %w", err)
+	}
+	schemaData, err := os.ReadFile(validateSchemaFile)
+	if err != nil {
+		return fmt.Errorf("failed to read schema file: %w", err)
+	}
+
+	v := &validation{}
+	// Values in JSON test files are located precisely; in other formats,
+	// problems are reported at the line on which the test case starts
+	var testLines lineMap
+	if runner.Format(validateTestsFile) == runner.FormatJSON {
+		testLines = jsonschema.Lines(testData)
+	}
+	schemaLines := lineMap(jsonschema.Lines(schemaData))
+
+	var validator *jsonschema.Schema
+	schema, err := runner.LoadSchema(validateSchemaFile)
+	if err != nil {
+		v.addLoadError(validateSchemaFile, err)
+	} else {
+		for _, name := range dialects {
+			d, ok := jsonschema.Dialects[name]
+			if !ok {
+				return fmt.Errorf("unknown dialect: %s (valid: %s)", name, strings.Join(slices.Sorted(maps.Keys(jsonschema.Dialects)), ", "))
+			}
+			issues, err := jsonschema.Check(schema, d)
+			if err != nil {
+				return err
+			}
+			for _, issue := range issues {
+				v.add(validateSchemaFile, schemaLines.line(issue.Pointer), severityWarning, "%s: %s: %s", d.Name, util.PathOrRoot(issue.Pointer), issue.Message)
+			}
+		}
+
+		validator, err = jsonschema.Compile(schema)
+		if err != nil {
+			v.add(validateSchemaFile, 0, severityError, "%v", err)
+		}
+	}
+
+	tests, err := runner.LoadTestFile(validateTestsFile, loadOptions(cfg, schema)...)
+	if err != nil {
+		v.addLoadError(validateTestsFile, err)
+	} else {
+		if len(tests) == 0 {
+			v.add(validateTestsFile, testLines.line(""), severityError, "no test cases")
+		}
+		if err := validateTests(v, validateTestsFile, tests, testLines, validator); err != nil {
+			return err
+		}
+	}
+
+	printProblems(cmd.OutOrStdout(), v, len(tests))
+
+	cmd.SilenceUsage = true
+	if v.count(severityError) > 0 {
+		return ErrValidationFailed
+	}
+	return nil
+}
+
+// validateTests checks each test case of file, validating expected values
+// against the schema if validator is not nil.
+func validateTests(v *validation, file string, tests []types.TestCase, lines lineMap, validator *jsonschema.Schema) error {
+	firstLine := make(map[string]int)
+	for i, test := range tests {
+		pointer := "/" + strconv.Itoa(i)
+		at := func(suffix string) int {
+			return cmp.Or(lines.line(pointer+suffix), test.Line)
+		}
+		line := at("")
+
+		name := strconv.Quote(test.Name)
+		switch first, seen := firstLine[test.Name]; {
+		case test.Name == "":
+			name = "#" + strconv.Itoa(i+1)
+			v.add(file, line, severityError, "test %s has no name", name)
+		case seen && first > 0:
+			v.add(file, at("/name"), severityError, "duplicate test name %s (first defined on line %d)", name, first)
+		case seen:
+			v.add(file, at("/name"), severityError, "duplicate test name %s", name)
+		default:
+			firstLine[test.Name] = line
+		}
+
+		if strings.TrimSpace(test.Input) == "" {
+			v.add(file, at("/input"), severityError, "test %s: input is empty", name)
+		}
+
+		if test.Expected == nil {
+			v.add(file, line, severityError, "test %s: expected is missing", name)
+			continue
+		}
+		if validator == nil {
+			continue
+		}
+
+		violations, err := validator.Validate(test.Expected)
+		if err != nil {
+			return err
+		}
+		matchers, err := matcherPaths(test.Expected)
+		if err != nil {
+			return err
+		}
+		for _, violation := range violations {
+			if slices.ContainsFunc(matchers, func(m string) bool { return withinPath(violation.Path, m) }) {
+				continue
+			}
+			v.add(file, at("/expected"+pathPointer(violation.Path)), severityError,
+				"test %s: %s: %s [schema: %s]", name, violation.Path, violation.Message, violation.Keyword)
+		}
+	}
+	return nil
+}
+
+// matcherPaths returns the paths of the matcher objects in an expected value.
+// Matchers stand in for values, so the schema does not apply to them.
+func matcherPaths(expected json.RawMessage) ([]string, error) {
+	var value any
+	if err := json.Unmarshal(expected, &value); err != nil {
+		return nil, fmt.Errorf("failed to parse expected value: %w", err)
+	}
+
+	var paths []string
+	var walk func(path string, value any)
+	walk = func(path string, value any) {
+		if compare.IsMatcher(value) {
+			paths = append(paths, util.PathOrRoot(path))
+			return
+		}
+		switch val := value.(type) {
+		case map[string]any:
+			for key, child := range val {
+				walk(util.JoinPath(path, key), child)
+			}
+		case []any:
+			for i, child := range val {
+				walk(fmt.Sprintf("%s[%d]", path, i), child)
+			}
+		}
+	}
+	walk("", value)
+	return paths, nil
+}
+
+// withinPath reports whether path is base or one of its descendants.
+func withinPath(path, base string) bool {
+	return base == "(root)" || path == base ||
+		strings.HasPrefix(path, base+".") || strings.HasPrefix(path, base+"[")
+}
+
+// printProblems prints each problem as "file:line: severity: message",
+// followed by a summary.
+func printProblems(w io.Writer, v *validation, tests int) {
+	red := color.New(color.FgRed).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+	green := color.New(color.FgGreen).SprintFunc()
+
+	slices.SortStableFunc(v.problems, func(a, b problem) int {
+		return cmp.Or(cmp.Compare(a.file, b.file), cmp.Compare(a.line, b.line))
+	})
+
+	for _, p := range v.problems {
+		location := p.file
+		if p.line > 0 {
+			location += ":" + strconv.Itoa(p.line)
+		}
+		severity := yellow(p.severity)
+		if p.severity == severityError {
+			severity = red(p.severity)
+		}
+		fmt.Fprintf(w, "%s: %s: %s\n", location, severity, p.message)
+	}
+
+	errs, warnings := v.count(severityError), v.count(severityWarning)
+	if errs == 0 {
+		if len(v.problems) > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "%s %s valid, %s\n", green("✓"), count(tests, "test"), count(warnings, "warning"))
+		return
+	}
+	fmt.Fprintf(w, "\n%s %s, %s\n", red("✗"), count(errs, "error"), count(warnings, "warning"))
+}
+
+// count formats n followed by a noun, pluralised if n is not 1.
+func count(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// lineMap maps the JSON pointer of each value in a document to the line on
+// which the value starts, as returned by jsonschema.Lines.
+type lineMap map[string]int
+
+// line returns the line of the value at pointer, or of its nearest ancestor
+// if the value does not exist, e.g. because it is a missing property.
+func (m lineMap) line(pointer string) int {
+	for {
+		if line, ok := m[pointer]; ok {
+			return line
+		}
+		if pointer == "" {
+			return 0
+		}
+		pointer = pointer[:strings.LastIndex(pointer, "/")]
+	}
+}
+
+// pathPointer converts a field path such as "items[0].name" to a JSON
+// pointer such as "/items/0/name".
+func pathPointer(path string) string {
+	if path == "(root)" {
+		return ""
+	}
+
+	var b strings.Builder
+	for _, part := range strings.Split(path, ".") {
+		name, rest, _ := strings.Cut(part, "[")
+		if name != "" {
+			b.WriteString("/" + jsonschema.EscapePointer(name))
+		}
+		for rest != "" {
+			index, after, _ := strings.Cut(rest, "]")
+			b.WriteString("/" + index)
+			rest = strings.TrimPrefix(after, "[")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,167 @@
+package cli
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateOutput(t *testing.T) {
+	schema := `{
+  "type": "object",
+  "properties": {
+    "name": {"type": "string"},
+    "age": {"type": "integer", "minimum": 0}
+  },
+  "required": ["name"]
+}`
+	strictSchema := `{
+  "type": "object",
+  "properties": {"name": {"type": "string"}},
+  "required": ["name"],
+  "additionalProperties": false
+}`
+
+	tests := []struct {
+		name    string
+		file    string
+		tests   string
+		schema  string
+		args    []string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:   "valid",
+			file:   "tests.json",
+			tests:  `[{"name": "ada", "input": "Ada", "expected": {"name": "Ada"}}]`,
+			schema: strictSchema,
+			want:   []string{"✓ 1 test valid, 0 warnings"},
+		},
+		{
+			name: "schema violation at the value",
+			file: "tests.json",
+			tests: `[
+  {
+    "name": "ada",
+    "input": "Ada",
+    "expected": {
+      "name": "Ada",
+      "age": -1
+    }
+  }
+]`,
+			schema:  schema,
+			args:    []string{"--dialect", ""},
+			want:    []string{`tests.json:7: error: test "ada": age: -1 is less than 0 [schema: minimum]`},
+			wantErr: true,
+		},
+		{
+			name: "duplicate name and empty input",
+			file: "tests.json",
+			tests: `[
+  {"name": "ada", "input": "Ada", "expected": {"name": "Ada"}},
+  {"name": "ada",
+   "input": " ",
+   "expected": {"name": "Ada"}}
+]`,
+			schema: strictSchema,
+			want: []string{
+				`tests.json:3: error: duplicate test name "ada" (first defined on line 2)`,
+				`tests.json:4: error: test "ada": input is empty`,
+				"✗ 2 errors, 0 warnings",
+			},
+			wantErr: true,
+		},
+		{
+			name:    "matchers skip the schema",
+			file:    "tests.json",
+			tests:   `[{"name": "ada", "input": "Ada", "expected": {"name": "Ada", "age": {"$gte": -5}}}]`,
+			schema:  schema,
+			args:    []string{"--dialect", ""},
+			want:    []string{"✓ 1 test valid"},
+			wantErr: false,
+		},
+		{
+			name: "YAML at the test line",
+			file: "tests.yaml",
+			tests: `- name: ada
+  input: Ada
+  expected: {name: Ada}
+- name: grace
+  input: Grace
+  expected: {age: 30}
+`,
+			schema:  strictSchema,
+			want:    []string{`tests.yaml:4: error: test "grace": name: required property is missing [schema: required]`},
+			wantErr: true,
+		},
+		{
+			name:    "syntax error",
+			file:    "tests.json",
+			tests:   "[\n  {\"name\": \"ada\",\n  \"input\": }\n]",
+			schema:  strictSchema,
+			want:    []string{"tests.json:3: error: "},
+			wantErr: true,
+		},
+		{
+			name:   "strict mode warnings",
+			file:   "tests.json",
+			tests:  `[{"name": "ada", "input": "Ada", "expected": {"name": "Ada"}}]`,
+			schema: schema,
+			want: []string{
+				"schema.json:1: warning: openai: (root): additionalProperties must be false",
+				`schema.json:1: warning: openai: (root): property "age" must be required`,
+				`schema.json:5: warning: openai: /properties/age: unsupported keyword "minimum"`,
+				"✓ 1 test valid, 3 warnings",
+			},
+		},
+		{
+			name:   "dialect checks skipped",
+			file:   "tests.json",
+			tests:  `[{"name": "ada", "input": "Ada", "expected": {"name": "Ada"}}]`,
+			schema: schema,
+			args:   []string{"--dialect", ""},
+			want:   []string{"✓ 1 test valid, 0 warnings"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			testsPath := writeFile(t, dir, tt.file, tt.tests)
+			schemaPath := writeFile(t, dir, "schema.json", tt.schema)
+
+			out, err := execute(t, append([]string{"validate", "--tests", testsPath, "--schema", schemaPath}, tt.args...)...)
+			if tt.wantErr != errors.Is(err, ErrValidationFailed) {
+				t.Errorf("error = %v, want validation failure %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("error = %v", err)
+			}
+
+			// Problems are reported relative to the files as given
+			out = strings.ReplaceAll(out, dir+string(filepath.Separator), "")
+			for _, want := range tt.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("output does not contain %q:\n%s", want, out)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateUnknownDialect(t *testing.T) {
+	dir := t.TempDir()
+	testsPath := writeFile(t, dir, "tests.json", `[]`)
+	schemaPath := writeFile(t, dir, "schema.json", `{"type": "object"}`)
+
+	_, err := execute(t, "validate", "--tests", testsPath, "--schema", schemaPath, "--dialect", "cobol")
+	if err == nil || !strings.Contains(err.Error(), "unknown dialect: cobol") {
+		t.Errorf("error = %v, want unknown dialect", err)
+	}
+	if exitCode(err) != ExitError {
+		t.Errorf("exit code = %d, want %d", exitCode(err), ExitError)
+	}
+}
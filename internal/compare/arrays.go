@@ -0,0 +1,178 @@
+package compare
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"go.carr.sh/litmus/internal/types"
+	"go.carr.sh/litmus/internal/util"
+)
+
+// Array comparison modes for Options.Array and PathOptions.Array.
+const (
+	// ArrayOrdered compares arrays element by element.
+	ArrayOrdered = "ordered"
+	// ArrayUnordered compares arrays as multisets: order is ignored, but
+	// duplicate elements must appear as many times as expected.
+	ArrayUnordered = "unordered"
+	// ArraySet compares arrays as sets: order and duplicates are ignored.
+	ArraySet = "set"
+)
+
+// compareUnordered compares two arrays regardless of order. Each expected
+// element is aligned with the actual element it differs from least, using
+// an optimal assignment, so that only genuinely missing, unexpected or wrong
+// elements are reported. Matched and missing elements are reported at their
+// expected index, and unexpected elements at their actual index. An expected
+// element counts as found for the list metrics if it matches the element it
+// is aligned with exactly. Candidate pairs are scored without the judge, which
+// is asked only about the aligned pairs.
+func (c *comparer) compareUnordered(path string, expected, actual []any, set bool) {
+	expectedIdx, actualIdx := indices(expected, set), indices(actual, set)
+
+	// Pad the cost matrix to a square, where pairing an element with a
+	// padding slot leaves it unmatched at the cost of its size
+	n := max(len(expectedIdx), len(actualIdx))
+	cost := make([][]int, n)
+	pairs := make([][]*comparer, n)
+	for i := range n {
+		cost[i] = make([]int, n)
+		pairs[i] = make([]*comparer, n)
+		for j := range n {
+			switch {
+			case i < len(expectedIdx) && j < len(actualIdx):
+				e, a := expectedIdx[i], actualIdx[j]
+				sub := c.candidate()
+				sub.compareValues(fmt.Sprintf("%s[%d]", path, e), expected[e], actual[a])
+				c.fail(sub.err)
+				pairs[i][j] = sub
+				cost[i][j] = len(sub.diffs)
+			case i < len(expectedIdx):
+				cost[i][j] = size(expected[expectedIdx[i]])
+			case j < len(actualIdx):
+				cost[i][j] = size(actual[actualIdx[j]])
+			}
+		}
+	}
+
+	var found int
+	for i, j := range hungarian(cost) {
+		switch {
+		case i < len(expectedIdx) && j < len(actualIdx):
+			pair := pairs[i][j]
+			if pair.estimated {
+				e, a := expectedIdx[i], actualIdx[j]
+				pair = c.child()
+				pair.compareValues(fmt.Sprintf("%s[%d]", path, e), expected[e], actual[a])
+				c.fail(pair.err)
+			}
+			if pair.err == nil && len(pair.diffs) == 0 {
+				found++
+			}
+			c.merge(pair)
+		case i < len(expectedIdx):
+			e := expectedIdx[i]
+			c.addMissing(fmt.Sprintf("%s[%d]", path, e), expected[e])
+		case j < len(actualIdx):
+			a := actualIdx[j]
+			c.addDiff(fmt.Sprintf("%s[%d]", path, a), types.DiffUnexpected, nil, actual[a])
+		}
+	}
+	c.countList(path, found, len(expectedIdx), len(actualIdx))
+}
+
+// countList adds the element counts of an array compared as a set or
+// multiset, given the number of expected elements found in the actual array.
+func (c *comparer) countList(path string, found, expected, actual int) {
+	key := NormalizePath(util.PathOrRoot(path))
+	c.lists[key] = c.lists[key].Add(types.ListCount{
+		TruePositives:  found,
+		FalsePositives: actual - found,
+		FalseNegatives: expected - found,
+	})
+}
+
+// indices returns the indices of the elements to align. For sets, only the
+// first of any duplicate elements is kept.
+func indices(values []any, set bool) []int {
+	idx := make([]int, 0, len(values))
+	seen := make(map[string]bool)
+	for i, v := range values {
+		if set {
+			b, _ := json.Marshal(v)
+			if seen[string(b)] {
+				continue
+			}
+			seen[string(b)] = true
+		}
+		idx = append(idx, i)
+	}
+	return idx
+}
+
+// size returns the number of leaf values in v, and at least 1.
+func size(v any) int {
+	return max(len(Flatten(v)), 1)
+}
+
+// hungarian solves the assignment problem for a square cost matrix,
+// returning the column assigned to each row such that the total cost is
+// minimal.
+func hungarian(cost [][]int) []int {
+	n := len(cost)
+	// u and v are the row and column potentials, p[j] is the row assigned to
+	// column j, and way records the augmenting path. All are 1-indexed, with
+	// column 0 as a sentinel.
+	u := make([]int, n+1)
+	v := make([]int, n+1)
+	p := make([]int, n+1)
+	way := make([]int, n+1)
+
+	for i := 1; i <= n; i++ {
+		p[0] = i
+		j0 := 0
+		minv := make([]int, n+1)
+		used := make([]bool, n+1)
+		for j := range minv {
+			minv[j] = math.MaxInt
+		}
+
+		for p[j0] != 0 {
+			used[j0] = true
+			i0, delta, j1 := p[j0], math.MaxInt, 0
+			for j := 1; j <= n; j++ {
+				if used[j] {
+					continue
+				}
+				if cur := cost[i0-1][j-1] - u[i0] - v[j]; cur < minv[j] {
+					minv[j], way[j] = cur, j0
+				}
+				if minv[j] < delta {
+					delta, j1 = minv[j], j
+				}
+			}
+			for j := 0; j <= n; j++ {
+				if used[j] {
+					u[p[j]] += delta
+					v[j] -= delta
+				} else {
+					minv[j] -= delta
+				}
+			}
+			j0 = j1
+		}
+
+		for j0 != 0 {
+			j1 := way[j0]
+			p[j0] = p[j1]
+			j0 = j1
+		}
+	}
+
+	assignment := make([]int, n)
+	for j := 1; j <= n; j++ {
+		assignment[p[j]-1] = j - 1
+	}
+	return assignment
+}
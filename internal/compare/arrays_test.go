@@ -0,0 +1,203 @@
+package compare
+
+import (
+	"math"
+	"testing"
+
+	"go.carr.sh/litmus/internal/types"
+)
+
+func TestHungarian(t *testing.T) {
+	tests := []struct {
+		name string
+		cost [][]int
+		// want is the minimal total cost.
+		want int
+	}{
+		{name: "empty", cost: [][]int{}, want: 0},
+		{name: "single", cost: [][]int{{7}}, want: 7},
+		{name: "identity", cost: [][]int{{0, 1}, {1, 0}}, want: 0},
+		{name: "swapped", cost: [][]int{{1, 0}, {0, 1}}, want: 0},
+		{
+			name: "greedy is not optimal",
+			// Pairing row 0 with its cheapest column forces row 1 onto 100
+			cost: [][]int{{1, 2}, {3, 100}},
+			want: 5,
+		},
+		{
+			name: "three by three",
+			cost: [][]int{{4, 1, 3}, {2, 0, 5}, {3, 2, 2}},
+			want: 5,
+		},
+		{
+			name: "ties",
+			cost: [][]int{{1, 1, 1}, {1, 1, 1}, {1, 1, 1}},
+			want: 3,
+		},
+		{
+			name: "four by four",
+			cost: [][]int{
+				{9, 2, 7, 8},
+				{6, 4, 3, 7},
+				{5, 8, 1, 8},
+				{7, 6, 9, 4},
+			},
+			want: 13,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assignment := hungarian(tt.cost)
+			if len(assignment) != len(tt.cost) {
+				t.Fatalf("len(assignment) = %d, want %d", len(assignment), len(tt.cost))
+			}
+
+			seen := make(map[int]bool)
+			total := 0
+			for i, j := range assignment {
+				if seen[j] {
+					t.Fatalf("column %d assigned twice in %v", j, assignment)
+				}
+				seen[j] = true
+				total += tt.cost[i][j]
+			}
+			if total != tt.want {
+				t.Errorf("total cost = %d, want %d (assignment %v)", total, tt.want, assignment)
+			}
+			if brute := bruteForce(tt.cost); total != brute {
+				t.Errorf("total cost = %d, brute force found %d", total, brute)
+			}
+		})
+	}
+}
+
+// bruteForce returns the minimal total cost of an assignment by trying every
+// permutation.
+func bruteForce(cost [][]int) int {
+	n := len(cost)
+	best := math.MaxInt
+	used := make([]bool, n)
+	var try func(row, total int)
+	try = func(row, total int) {
+		if row == n {
+			best = min(best, total)
+			return
+		}
+		for j := range n {
+			if !used[j] {
+				used[j] = true
+				try(row+1, total+cost[row][j])
+				used[j] = false
+			}
+		}
+	}
+	try(0, 0)
+	return best
+}
+
+func TestCompareLists(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     string
+		expected string
+		actual   string
+		// want are the list counts, or nil if none should be recorded.
+		want map[string]types.ListCount
+	}{
+		{
+			name:     "ordered arrays are not counted",
+			mode:     ArrayOrdered,
+			expected: `{"tags":["a","b"]}`,
+			actual:   `{"tags":["b","a"]}`,
+			want:     map[string]types.ListCount{},
+		},
+		{
+			name:     "set",
+			mode:     ArraySet,
+			expected: `{"tags":["a","b","b","c"]}`,
+			actual:   `{"tags":["b","a","d"]}`,
+			want:     map[string]types.ListCount{"tags": {TruePositives: 2, FalsePositives: 1, FalseNegatives: 1}},
+		},
+		{
+			name:     "unordered counts duplicates",
+			mode:     ArrayUnordered,
+			expected: `{"tags":["a","b","b","c"]}`,
+			actual:   `{"tags":["b","a","d"]}`,
+			want:     map[string]types.ListCount{"tags": {TruePositives: 2, FalsePositives: 1, FalseNegatives: 2}},
+		},
+		{
+			name:     "empty arrays",
+			mode:     ArraySet,
+			expected: `{"tags":[]}`,
+			actual:   `{"tags":[]}`,
+			want:     map[string]types.ListCount{"tags": {}},
+		},
+		{
+			name:     "missing array",
+			mode:     ArraySet,
+			expected: `{"tags":["a","b"]}`,
+			actual:   `{}`,
+			want:     map[string]types.ListCount{"tags": {FalseNegatives: 2}},
+		},
+		{
+			name:     "array of a different type",
+			mode:     ArraySet,
+			expected: `{"tags":["a","b"]}`,
+			actual:   `{"tags":"a"}`,
+			want:     map[string]types.ListCount{"tags": {FalseNegatives: 2}},
+		},
+		{
+			name:     "objects must match exactly",
+			mode:     ArraySet,
+			expected: `{"people":[{"name":"Ada","age":36},{"name":"Alan","age":41}]}`,
+			actual:   `{"people":[{"name":"Alan","age":41},{"name":"Ada","age":37}]}`,
+			want:     map[string]types.ListCount{"people": {TruePositives: 1, FalsePositives: 1, FalseNegatives: 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Compare([]byte(tt.expected), []byte(tt.actual), Options{Array: tt.mode}, nil)
+			if err != nil {
+				t.Fatalf("Compare() error = %v", err)
+			}
+			if len(result.Lists) != len(tt.want) {
+				t.Fatalf("Lists = %v, want %v", result.Lists, tt.want)
+			}
+			for path, want := range tt.want {
+				if got, ok := result.Lists[path]; !ok || got != want {
+					t.Errorf("Lists[%q] = %+v, want %+v", path, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCompareUnorderedJudgesAlignedPairsOnly(t *testing.T) {
+	var calls []string
+	judge := func(opts JudgeOptions, path string, expected, actual any) (*Verdict, error) {
+		calls = append(calls, path)
+		return &Verdict{Score: 1}, nil
+	}
+	opts := Options{
+		Array: ArraySet,
+		Paths: map[string]PathOptions{"notes[*].text": {Judge: &JudgeOptions{Model: "judge"}}},
+	}
+
+	result, err := Compare(
+		[]byte(`{"notes":[{"id":1,"text":"the cat sat"},{"id":2,"text":"a dog ran"},{"id":3,"text":"birds flew"}]}`),
+		[]byte(`{"notes":[{"id":2,"text":"a dog ran off"},{"id":3,"text":"birds flew"},{"id":1,"text":"the cat sat down"}]}`),
+		opts, judge)
+	if err != nil {
+		t.Fatalf("Compare() error = %v", err)
+	}
+	if len(result.Diffs) != 0 {
+		t.Errorf("Diffs = %v, want none", result.Diffs)
+	}
+	// Equal texts match without a judge call, leaving one call for each of
+	// the two aligned pairs that differ
+	if len(calls) != 2 {
+		t.Errorf("judge called %d times (%v), want 2", len(calls), calls)
+	}
+}
@@ -7,11 +7,32 @@ import (
 	"reflect"
 
 	"go.carr.sh/litmus/internal/types"
+	"go.carr.sh/litmus/internal/util"
 )
 
+// Result is the outcome of a comparison.
+type Result struct {
+	// Diffs are the field differences found.
+	Diffs []types.FieldDiff
+	// Score is the weighted share of leaf fields that matched, from 0 to 1.
+	// Leaves are the scalar values, empty objects and arrays, and matchers of
+	// the expected value, plus any unexpected fields in the actual value.
+	Score float64
+	// Fields counts the matched and total leaves for each field, keyed by
+	// path with array indices normalized to [*].
+	Fields map[string]types.FieldCount
+	// Lists counts the elements of each array field compared as a set or
+	// multiset, keyed by path with array indices normalized to [*].
+	Lists map[string]types.ListCount
+	// Judgements are the verdicts of the judge on the judged fields,
+	// including those that matched.
+	Judgements []types.Judgement
+}
+
 // Compare performs a deep comparison between expected and actual JSON values.
-// It returns a list of field differences found.
-func Compare(expected, actual json.RawMessage) ([]types.FieldDiff, error) {
+// It returns the field differences found and a field-level score. Paths with
+// judge options are scored by judge, which may be nil if there are none.
+func Compare(expected, actual json.RawMessage, opts Options, judge JudgeFunc) (*Result, error) {
 	var expectedVal, actualVal any
 
 	if err := json.Unmarshal(expected, &expectedVal); err != nil {
@@ -22,23 +43,92 @@ func Compare(expected, actual json.RawMessage) ([]types.FieldDiff, error) {
 		return nil, fmt.Errorf("failed to parse actual JSON: %w", err)
 	}
 
-	var diffs []types.FieldDiff
-	compareValues("", expectedVal, actualVal, &diffs)
-	return diffs, nil
+	c := newComparer(opts, judge)
+	c.compareValues("", expectedVal, actualVal)
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	score := 1.0
+	if c.total > 0 {
+		score = c.matched / c.total
+	}
+	return &Result{Diffs: c.diffs, Score: score, Fields: c.fields, Lists: c.lists, Judgements: c.judgements}, nil
+}
+
+// comparer holds the options and collected differences of a comparison.
+type comparer struct {
+	// opts are the comparison options.
+	opts Options
+	// judge scores the fields at judged paths.
+	judge JudgeFunc
+	// diffs are the differences found so far.
+	diffs []types.FieldDiff
+	// judgements are the judge's verdicts so far.
+	judgements []types.Judgement
+	// err is the first error found in the expected value, such as an
+	// invalid matcher.
+	err error
+	// matched and total are the weights of the matched and of all leaves
+	// compared so far.
+	matched, total float64
+	// fields counts the matched and total leaves for each normalized path.
+	fields map[string]types.FieldCount
+	// lists counts the elements of each array compared as a set or
+	// multiset, by normalized path.
+	lists map[string]types.ListCount
+	// scoring is set on comparers that only score candidate pairs for
+	// alignment. They estimate judged fields by string similarity rather
+	// than paying for a judge call on every pair.
+	scoring bool
+	// estimated is set if a judged field was estimated.
+	estimated bool
+}
+
+// newComparer creates a comparer with the given options and judge.
+func newComparer(opts Options, judge JudgeFunc) *comparer {
+	return &comparer{
+		opts:   opts,
+		judge:  judge,
+		fields: make(map[string]types.FieldCount),
+		lists:  make(map[string]types.ListCount),
+	}
+}
+
+// candidate returns a comparer that scores a candidate pair without calling
+// the judge.
+func (c *comparer) candidate() *comparer {
+	sub := newComparer(c.opts, nil)
+	sub.scoring = true
+	return sub
+}
+
+// child returns a comparer for a final pair, which calls the judge unless c
+// itself only scores candidates.
+func (c *comparer) child() *comparer {
+	sub := newComparer(c.opts, c.judge)
+	sub.scoring = c.scoring
+	return sub
 }
 
 // compareValues recursively compares two values and collects differences.
-func compareValues(path string, expected, actual any, diffs *[]types.FieldDiff) {
+func (c *comparer) compareValues(path string, expected, actual any) {
+	if m, ok := asMatcher(expected); ok {
+		c.match(path, m, actual, true)
+		return
+	}
+	if j, ok := c.opts.judge(path); ok && expected != nil && actual != nil {
+		c.judgeField(path, j, expected, actual)
+		return
+	}
+
 	// Handle nil cases
 	if expected == nil && actual == nil {
+		c.pass(path)
 		return
 	}
 	if expected == nil || actual == nil {
-		*diffs = append(*diffs, types.FieldDiff{
-			Path:     pathOrRoot(path),
-			Expected: expected,
-			Actual:   actual,
-		})
+		c.addDiff(path, types.DiffTypeMismatch, expected, actual)
 		return
 	}
 
@@ -47,101 +137,270 @@ func compareValues(path string, expected, actual any, diffs *[]types.FieldDiff)
 
 	// Type mismatch
 	if expectedType != actualType {
-		*diffs = append(*diffs, types.FieldDiff{
-			Path:     pathOrRoot(path),
-			Expected: expected,
-			Actual:   actual,
-		})
+		c.addDiff(path, types.DiffTypeMismatch, expected, actual)
 		return
 	}
 
 	switch exp := expected.(type) {
 	case map[string]any:
 		act := actual.(map[string]any)
-		compareObjects(path, exp, act, diffs)
+		c.compareObjects(path, exp, act)
 
 	case []any:
 		act := actual.([]any)
-		compareArrays(path, exp, act, diffs)
+		c.compareArrays(path, exp, act)
+
+	case float64:
+		c.compareNumbers(path, exp, actual.(float64))
+
+	case string:
+		c.compareStrings(path, exp, actual.(string))
 
 	default:
 		// Scalar comparison
 		if !reflect.DeepEqual(expected, actual) {
-			*diffs = append(*diffs, types.FieldDiff{
-				Path:     pathOrRoot(path),
-				Expected: expected,
-				Actual:   actual,
-			})
+			c.addDiff(path, types.DiffChanged, expected, actual)
+		} else {
+			c.pass(path)
 		}
 	}
 }
 
 // compareObjects compares two JSON objects field by field.
-func compareObjects(path string, expected, actual map[string]any, diffs *[]types.FieldDiff) {
+func (c *comparer) compareObjects(path string, expected, actual map[string]any) {
+	if c.opts.NullAsAbsent {
+		expected, actual = withoutNulls(expected), withoutNulls(actual)
+	}
+	if len(expected) == 0 && len(actual) == 0 {
+		c.pass(path)
+		return
+	}
+
 	// Check all expected fields
 	for key, expectedVal := range expected {
-		newPath := joinPath(path, key)
+		newPath := util.JoinPath(path, key)
 		if actualVal, exists := actual[key]; exists {
-			compareValues(newPath, expectedVal, actualVal, diffs)
+			c.compareValues(newPath, expectedVal, actualVal)
 		} else {
-			*diffs = append(*diffs, types.FieldDiff{
-				Path:     newPath,
-				Expected: expectedVal,
-				Actual:   nil,
-			})
+			c.addMissing(newPath, expectedVal)
 		}
 	}
 
 	// Check for unexpected fields in actual
+	if c.opts.IgnoreUnexpected {
+		return
+	}
 	for key, actualVal := range actual {
 		if _, exists := expected[key]; !exists {
-			newPath := joinPath(path, key)
-			*diffs = append(*diffs, types.FieldDiff{
-				Path:     newPath,
-				Expected: nil,
-				Actual:   actualVal,
-			})
+			c.addDiff(util.JoinPath(path, key), types.DiffUnexpected, nil, actualVal)
 		}
 	}
 }
 
-// compareArrays compares two JSON arrays element by element.
-func compareArrays(path string, expected, actual []any, diffs *[]types.FieldDiff) {
+// withoutNulls returns a copy of an object without its null fields.
+func withoutNulls(obj map[string]any) map[string]any {
+	out := make(map[string]any, len(obj))
+	for key, v := range obj {
+		if v != nil {
+			out[key] = v
+		}
+	}
+	return out
+}
+
+// compareArrays compares two JSON arrays using the array mode for the path.
+func (c *comparer) compareArrays(path string, expected, actual []any) {
+	if len(expected) == 0 && len(actual) == 0 {
+		if c.opts.countsList(path) {
+			c.countList(path, 0, 0, 0)
+		}
+		c.pass(path)
+		return
+	}
+
+	switch c.opts.arrayMode(path) {
+	case ArrayUnordered:
+		c.compareUnordered(path, expected, actual, false)
+		return
+	case ArraySet:
+		c.compareUnordered(path, expected, actual, true)
+		return
+	}
+
 	maxLen := max(len(expected), len(actual))
 
 	for i := range maxLen {
 		newPath := fmt.Sprintf("%s[%d]", path, i)
 
 		if i >= len(expected) {
-			*diffs = append(*diffs, types.FieldDiff{
-				Path:     newPath,
-				Expected: nil,
-				Actual:   actual[i],
-			})
+			c.addDiff(newPath, types.DiffUnexpected, nil, actual[i])
 		} else if i >= len(actual) {
-			*diffs = append(*diffs, types.FieldDiff{
-				Path:     newPath,
-				Expected: expected[i],
-				Actual:   nil,
-			})
+			c.addMissing(newPath, expected[i])
 		} else {
-			compareValues(newPath, expected[i], actual[i], diffs)
+			c.compareValues(newPath, expected[i], actual[i])
 		}
 	}
 }
 
-// joinPath creates a dot-separated path.
-func joinPath(base, key string) string {
-	if base == "" {
-		return key
+// compareNumbers compares two numbers within the tolerance for the path,
+// recording the delta if they differ.
+func (c *comparer) compareNumbers(path string, expected, actual float64) {
+	delta := actual - expected
+	if delta == 0 || c.opts.tolerance(path).Allows(expected, actual) {
+		c.pass(path)
+		return
 	}
-	return base + "." + key
+
+	c.record(types.FieldDiff{
+		Path:     util.PathOrRoot(path),
+		Kind:     types.DiffChanged,
+		Expected: expected,
+		Actual:   actual,
+		Delta:    &delta,
+	})
 }
 
-// pathOrRoot returns the path or "(root)" if empty.
-func pathOrRoot(path string) string {
-	if path == "" {
-		return "(root)"
+// compareStrings compares two strings using the string matching options for
+// the path, recording the similarity if one was computed.
+func (c *comparer) compareStrings(path, expected, actual string) {
+	if expected == actual {
+		c.pass(path)
+		return
+	}
+	matched, similarity := c.opts.stringMatch(path).Match(expected, actual)
+	if matched {
+		c.pass(path)
+		return
+	}
+
+	c.record(types.FieldDiff{
+		Path:       util.PathOrRoot(path),
+		Kind:       types.DiffChanged,
+		Expected:   expected,
+		Actual:     actual,
+		Similarity: similarity,
+	})
+}
+
+// fail records an error, keeping the first.
+func (c *comparer) fail(err error) {
+	if c.err == nil {
+		c.err = err
+	}
+}
+
+// addMissing records an expected value that is missing from the actual
+// value, unless it is a matcher that allows the value to be absent.
+func (c *comparer) addMissing(path string, expected any) {
+	if m, ok := asMatcher(expected); ok {
+		c.match(path, m, nil, false)
+		return
+	}
+	c.addDiff(path, types.DiffMissing, expected, nil)
+}
+
+// addDiff records a difference of the given kind at path.
+func (c *comparer) addDiff(path, kind string, expected, actual any) {
+	c.record(types.FieldDiff{
+		Path:     util.PathOrRoot(path),
+		Kind:     kind,
+		Expected: expected,
+		Actual:   actual,
+	})
+}
+
+// record adds a difference, scoring every leaf of the expected value (or of
+// the actual value, for an unexpected field) as unmatched.
+func (c *comparer) record(diff types.FieldDiff) {
+	c.diffs = append(c.diffs, diff)
+
+	v := diff.Expected
+	if v == nil {
+		v = diff.Actual
+	}
+	path := diff.Path
+	if path == "(root)" {
+		path = ""
+	}
+
+	// An array compared with anything but an array shares no elements
+	expected, _ := diff.Expected.([]any)
+	actual, _ := diff.Actual.([]any)
+	if (expected != nil || actual != nil) && c.opts.countsList(path) {
+		set := c.opts.arrayMode(path) == ArraySet
+		c.countList(path, 0, len(indices(expected, set)), len(indices(actual, set)))
+	}
+	c.forEachLeaf(path, v, func(leaf string) {
+		c.total += c.opts.weight(leaf)
+		c.count(leaf, false)
+	})
+}
+
+// pass scores the leaf at path as matched.
+func (c *comparer) pass(path string) {
+	w := c.opts.weight(path)
+	c.matched += w
+	c.total += w
+	c.count(path, true)
+}
+
+// count tallies a leaf under its normalized path.
+func (c *comparer) count(path string, matched bool) {
+	key := NormalizePath(util.PathOrRoot(path))
+	fc := c.fields[key]
+	fc.Total++
+	if matched {
+		fc.Matched++
+	}
+	c.fields[key] = fc
+}
+
+// merge adds the differences, verdicts, scores and counts of a
+// sub-comparer.
+func (c *comparer) merge(sub *comparer) {
+	c.diffs = append(c.diffs, sub.diffs...)
+	c.judgements = append(c.judgements, sub.judgements...)
+	c.estimated = c.estimated || sub.estimated
+	c.matched += sub.matched
+	c.total += sub.total
+	for path, fc := range sub.fields {
+		total := c.fields[path]
+		total.Matched += fc.Matched
+		total.Total += fc.Total
+		c.fields[path] = total
+	}
+	for path, lc := range sub.lists {
+		c.lists[path] = c.lists[path].Add(lc)
+	}
+}
+
+// forEachLeaf calls fn with the path of every leaf of v. Matchers, judged
+// values, empty objects and empty arrays are leaves.
+func (c *comparer) forEachLeaf(path string, v any, fn func(string)) {
+	if _, ok := asMatcher(v); ok {
+		fn(path)
+		return
+	}
+	if _, ok := c.opts.judge(path); ok {
+		fn(path)
+		return
+	}
+	switch val := v.(type) {
+	case map[string]any:
+		if len(val) == 0 {
+			fn(path)
+		}
+		for key, child := range val {
+			c.forEachLeaf(util.JoinPath(path, key), child, fn)
+		}
+	case []any:
+		if len(val) == 0 {
+			fn(path)
+		}
+		for i, child := range val {
+			c.forEachLeaf(fmt.Sprintf("%s[%d]", path, i), child, fn)
+		}
+	default:
+		fn(path)
 	}
-	return path
 }
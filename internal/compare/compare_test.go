@@ -0,0 +1,128 @@
+package compare
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"go.carr.sh/litmus/internal/types"
+)
+
+func TestCompareDiffKinds(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     Options
+		expected string
+		actual   string
+		want     []types.FieldDiff
+	}{
+		{name: "equal", expected: `{"a":1,"b":"x"}`, actual: `{"b":"x","a":1}`},
+		{
+			name:     "changed",
+			expected: `{"a":"x"}`,
+			actual:   `{"a":"y"}`,
+			want:     []types.FieldDiff{{Path: "a", Kind: types.DiffChanged, Expected: "x", Actual: "y"}},
+		},
+		{
+			name:     "missing",
+			expected: `{"a":1,"b":2}`,
+			actual:   `{"a":1}`,
+			want:     []types.FieldDiff{{Path: "b", Kind: types.DiffMissing, Expected: 2.0}},
+		},
+		{
+			name:     "unexpected",
+			expected: `{"a":1}`,
+			actual:   `{"a":1,"b":2}`,
+			want:     []types.FieldDiff{{Path: "b", Kind: types.DiffUnexpected, Actual: 2.0}},
+		},
+		{
+			name:     "unexpected ignored",
+			opts:     Options{IgnoreUnexpected: true},
+			expected: `{"a":1}`,
+			actual:   `{"a":1,"b":2}`,
+		},
+		{
+			name:     "number and string",
+			expected: `{"a":25}`,
+			actual:   `{"a":"25"}`,
+			want:     []types.FieldDiff{{Path: "a", Kind: types.DiffTypeMismatch, Expected: 25.0, Actual: "25"}},
+		},
+		{
+			name:     "explicit null",
+			expected: `{"a":"x"}`,
+			actual:   `{"a":null}`,
+			want:     []types.FieldDiff{{Path: "a", Kind: types.DiffTypeMismatch, Expected: "x"}},
+		},
+		{
+			name:     "null expected",
+			expected: `{"a":null}`,
+			actual:   `{"a":"x"}`,
+			want:     []types.FieldDiff{{Path: "a", Kind: types.DiffTypeMismatch, Actual: "x"}},
+		},
+		{name: "both null", expected: `{"a":null}`, actual: `{"a":null}`},
+		{
+			name:     "null and absent",
+			expected: `{"a":null}`,
+			actual:   `{}`,
+			want:     []types.FieldDiff{{Path: "a", Kind: types.DiffMissing}},
+		},
+		{
+			name:     "absent and null",
+			expected: `{}`,
+			actual:   `{"a":null}`,
+			want:     []types.FieldDiff{{Path: "a", Kind: types.DiffUnexpected}},
+		},
+		{name: "null as absent", opts: Options{NullAsAbsent: true}, expected: `{"a":null,"b":1}`, actual: `{"b":1}`},
+		{name: "absent as null", opts: Options{NullAsAbsent: true}, expected: `{"b":1}`, actual: `{"a":null,"b":1}`},
+		{name: "null as absent when nested", opts: Options{NullAsAbsent: true}, expected: `{"o":{"a":null}}`, actual: `{"o":{}}`},
+		{
+			name:     "null as absent still requires values",
+			opts:     Options{NullAsAbsent: true},
+			expected: `{"a":"x"}`,
+			actual:   `{"a":null}`,
+			want:     []types.FieldDiff{{Path: "a", Kind: types.DiffMissing, Expected: "x"}},
+		},
+		{
+			name:     "array element missing",
+			expected: `{"a":[1,2]}`,
+			actual:   `{"a":[1]}`,
+			want:     []types.FieldDiff{{Path: "a[1]", Kind: types.DiffMissing, Expected: 2.0}},
+		},
+		{
+			name:     "array element unexpected",
+			expected: `{"a":[1]}`,
+			actual:   `{"a":[1,2]}`,
+			want:     []types.FieldDiff{{Path: "a[1]", Kind: types.DiffUnexpected, Actual: 2.0}},
+		},
+		{
+			name:     "object and array",
+			expected: `{"a":{}}`,
+			actual:   `{"a":[]}`,
+			want:     []types.FieldDiff{{Path: "a", Kind: types.DiffTypeMismatch, Expected: map[string]any{}, Actual: []any{}}},
+		},
+		{
+			name:     "root",
+			expected: `true`,
+			actual:   `false`,
+			want:     []types.FieldDiff{{Path: "(root)", Kind: types.DiffChanged, Expected: true, Actual: false}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Compare([]byte(tt.expected), []byte(tt.actual), tt.opts, nil)
+			if err != nil {
+				t.Fatalf("Compare() error = %v", err)
+			}
+			diffs := slices.Clone(result.Diffs)
+			slices.SortFunc(diffs, func(a, b types.FieldDiff) int { return strings.Compare(a.Path, b.Path) })
+			if len(diffs) == 0 && len(tt.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(diffs, tt.want) {
+				t.Errorf("Diffs = %+v, want %+v", diffs, tt.want)
+			}
+		})
+	}
+}
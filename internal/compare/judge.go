@@ -0,0 +1,174 @@
+package compare
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+
+	"go.carr.sh/litmus/internal/types"
+	"go.carr.sh/litmus/internal/util"
+)
+
+// defaultJudgeThreshold is the minimum judge score for a field to match when
+// no threshold is set.
+const defaultJudgeThreshold = 0.7
+
+// JudgeOptions configure how a judge model scores free-text fields that
+// cannot be compared exactly.
+type JudgeOptions struct {
+	// Model is the judge model, with an optional provider prefix.
+	Model string `json:"model,omitempty"`
+	// Rubric tells the judge how to score the actual value against the
+	// expected one. If empty, a rubric for semantic equivalence is used.
+	Rubric string `json:"rubric,omitempty"`
+	// Threshold is the minimum score, from 0 to 1, for the field to match.
+	// It defaults to 0.7 if nil, so that a threshold of 0 can be set.
+	Threshold *float64 `json:"threshold,omitempty"`
+}
+
+// UnmarshalJSON accepts true as shorthand for an empty object, so that a path
+// can be judged with the global judge options.
+func (j *JudgeOptions) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("true")) {
+		*j = JudgeOptions{}
+		return nil
+	}
+	type plain JudgeOptions
+	return json.Unmarshal(data, (*plain)(j))
+}
+
+// Verdict is a judge's assessment of a field.
+type Verdict struct {
+	// Score is how well the actual value matches the expected one, from 0 to 1.
+	Score float64 `json:"score"`
+	// Rationale explains the score.
+	Rationale string `json:"rationale"`
+	// Cost is the cost of the judge request in USD, or nil if the provider
+	// did not report it.
+	Cost *float64 `json:"-"`
+}
+
+// JudgeFunc asks a judge model to score the actual value of the field at path
+// against the expected value.
+type JudgeFunc func(opts JudgeOptions, path string, expected, actual any) (*Verdict, error)
+
+// judge returns the judge options for a path, with any unset options taken
+// from the global judge options, and whether the path is judged at all.
+func (o Options) judge(path string) (JudgeOptions, bool) {
+	po, ok := o.path(path)
+	if !ok || po.Judge == nil {
+		return JudgeOptions{}, false
+	}
+	threshold := po.Judge.Threshold
+	if threshold == nil {
+		threshold = o.Judge.Threshold
+	}
+	if threshold == nil {
+		t := defaultJudgeThreshold
+		threshold = &t
+	}
+	return JudgeOptions{
+		Model:     cmp.Or(po.Judge.Model, o.Judge.Model),
+		Rubric:    cmp.Or(po.Judge.Rubric, o.Judge.Rubric),
+		Threshold: threshold,
+	}, true
+}
+
+// JudgeModels returns the judge model of every judged path, without
+// duplicates.
+func (o Options) JudgeModels() []string {
+	var models []string
+	for _, path := range slices.Sorted(maps.Keys(o.Paths)) {
+		if j, ok := o.judge(path); ok && j.Model != "" && !slices.Contains(models, j.Model) {
+			models = append(models, j.Model)
+		}
+	}
+	return models
+}
+
+// judgeField scores a field with the judge, keeping the verdict and
+// recording a diff with the score and rationale if it falls below the
+// threshold. Equal values match without asking the judge.
+func (c *comparer) judgeField(path string, opts JudgeOptions, expected, actual any) {
+	if reflect.DeepEqual(expected, actual) {
+		c.pass(path)
+		return
+	}
+	if c.scoring {
+		c.estimate(path, opts, expected, actual)
+		return
+	}
+	if c.judge == nil {
+		c.fail(fmt.Errorf("%s: no judge is available", util.PathOrRoot(path)))
+		return
+	}
+	verdict, err := c.judge(opts, util.PathOrRoot(path), expected, actual)
+	if err != nil {
+		c.fail(fmt.Errorf("failed to judge %s: %w", util.PathOrRoot(path), err))
+		return
+	}
+	passed := verdict.Score >= *opts.Threshold
+	c.judgements = append(c.judgements, types.Judgement{
+		Path:      util.PathOrRoot(path),
+		Score:     verdict.Score,
+		Rationale: verdict.Rationale,
+		Passed:    passed,
+		Cost:      verdict.Cost,
+	})
+	if passed {
+		c.pass(path)
+		return
+	}
+
+	c.record(types.FieldDiff{
+		Path:       util.PathOrRoot(path),
+		Kind:       types.DiffChanged,
+		Expected:   expected,
+		Actual:     actual,
+		JudgeScore: &verdict.Score,
+		Rationale:  verdict.Rationale,
+	})
+}
+
+// estimate stands in for the judge when scoring candidate pairs, matching a
+// field if the Levenshtein similarity of the values, as JSON if they are not
+// strings, reaches the judge threshold.
+func (c *comparer) estimate(path string, opts JudgeOptions, expected, actual any) {
+	c.estimated = true
+	e, ok := expected.(string)
+	if !ok {
+		e = util.Describe(expected)
+	}
+	a, ok := actual.(string)
+	if !ok {
+		a = util.Describe(actual)
+	}
+	sim := levenshteinSimilarity(e, a)
+	if sim >= *opts.Threshold {
+		c.pass(path)
+		return
+	}
+
+	c.record(types.FieldDiff{
+		Path:       util.PathOrRoot(path),
+		Kind:       types.DiffChanged,
+		Expected:   expected,
+		Actual:     actual,
+		Similarity: &sim,
+	})
+}
+
+// validate checks that a judged path has a model and a valid threshold.
+func (j JudgeOptions) validate() error {
+	if j.Model == "" {
+		return fmt.Errorf("judge model required: set compare.judge.model")
+	}
+	if j.Threshold != nil && (*j.Threshold < 0 || *j.Threshold > 1) {
+		return fmt.Errorf("judge threshold must be between 0 and 1")
+	}
+	return nil
+}
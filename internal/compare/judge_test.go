@@ -0,0 +1,120 @@
+package compare
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestJudgeField(t *testing.T) {
+	tests := []struct {
+		name      string
+		global    JudgeOptions
+		path      JudgeOptions
+		score     float64
+		expected  string
+		actual    string
+		wantDiff  bool
+		wantCalls int
+	}{
+		{name: "default threshold met", score: 0.7, expected: `{"s":"a cat"}`, actual: `{"s":"one cat"}`, wantCalls: 1},
+		{name: "default threshold missed", score: 0.69, expected: `{"s":"a cat"}`, actual: `{"s":"a dog"}`, wantDiff: true, wantCalls: 1},
+		{name: "zero threshold", path: JudgeOptions{Threshold: ptr(0.0)}, score: 0, expected: `{"s":"a cat"}`, actual: `{"s":"a dog"}`, wantCalls: 1},
+		{name: "global zero threshold", global: JudgeOptions{Model: "judge", Threshold: ptr(0.0)}, score: 0, expected: `{"s":"a cat"}`, actual: `{"s":"a dog"}`, wantCalls: 1},
+		{name: "path threshold overrides global", global: JudgeOptions{Model: "judge", Threshold: ptr(0.0)}, path: JudgeOptions{Threshold: ptr(0.9)}, score: 0.8, expected: `{"s":"a cat"}`, actual: `{"s":"one cat"}`, wantDiff: true, wantCalls: 1},
+		{name: "equal values", score: 0, expected: `{"s":"a cat"}`, actual: `{"s":"a cat"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int
+			cost := 0.002
+			judge := func(opts JudgeOptions, path string, expected, actual any) (*Verdict, error) {
+				calls++
+				return &Verdict{Score: tt.score, Rationale: "because", Cost: &cost}, nil
+			}
+			pathOpts := tt.path
+			pathOpts.Model = "judge"
+			opts := Options{Judge: tt.global, Paths: map[string]PathOptions{"s": {Judge: &pathOpts}}}
+			if err := opts.Validate(); err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+
+			result, err := Compare([]byte(tt.expected), []byte(tt.actual), opts, judge)
+			if err != nil {
+				t.Fatalf("Compare() error = %v", err)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("judge called %d times, want %d", calls, tt.wantCalls)
+			}
+			if (len(result.Diffs) > 0) != tt.wantDiff {
+				t.Errorf("Diffs = %+v, want diff %v", result.Diffs, tt.wantDiff)
+			}
+
+			// Every verdict is kept, whether or not the field matched
+			if len(result.Judgements) != tt.wantCalls {
+				t.Fatalf("Judgements = %+v, want %d", result.Judgements, tt.wantCalls)
+			}
+			for _, j := range result.Judgements {
+				if j.Path != "s" || j.Score != tt.score || j.Rationale != "because" || j.Passed == tt.wantDiff {
+					t.Errorf("Judgement = %+v", j)
+				}
+				if j.Cost == nil || *j.Cost != cost {
+					t.Errorf("Judgement cost = %v, want %v", j.Cost, cost)
+				}
+			}
+		})
+	}
+}
+
+func TestJudgeOneOfKeepsVerdict(t *testing.T) {
+	judge := func(opts JudgeOptions, path string, expected, actual any) (*Verdict, error) {
+		return &Verdict{Score: 0.9}, nil
+	}
+	opts := Options{Paths: map[string]PathOptions{"s": {Judge: &JudgeOptions{Model: "judge"}}}}
+
+	result, err := Compare([]byte(`{"s":{"$oneOf":["a big cat","a small dog"]}}`), []byte(`{"s":"a large cat"}`), opts, judge)
+	if err != nil {
+		t.Fatalf("Compare() error = %v", err)
+	}
+	if len(result.Diffs) != 0 {
+		t.Errorf("Diffs = %+v, want none", result.Diffs)
+	}
+	if len(result.Judgements) != 1 || !result.Judgements[0].Passed {
+		t.Errorf("Judgements = %+v, want one that passed", result.Judgements)
+	}
+}
+
+func TestJudgeOptionsThreshold(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		want    float64
+		wantErr bool
+	}{
+		{name: "default", config: `{"model":"judge"}`, want: defaultJudgeThreshold},
+		{name: "zero", config: `{"model":"judge","threshold":0}`, want: 0},
+		{name: "set", config: `{"model":"judge","threshold":0.85}`, want: 0.85},
+		{name: "above one", config: `{"model":"judge","threshold":1.5}`, wantErr: true},
+		{name: "negative", config: `{"model":"judge","threshold":-0.1}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var j JudgeOptions
+			if err := json.Unmarshal([]byte(tt.config), &j); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			opts := Options{Paths: map[string]PathOptions{"s": {Judge: &j}}}
+			if err := opts.Validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			resolved, ok := opts.judge("s")
+			if !ok || resolved.Threshold == nil || *resolved.Threshold != tt.want {
+				t.Errorf("threshold = %v, want %v", resolved.Threshold, tt.want)
+			}
+		})
+	}
+}
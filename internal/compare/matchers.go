@@ -0,0 +1,206 @@
+package compare
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+
+	"go.carr.sh/litmus/internal/types"
+	"go.carr.sh/litmus/internal/util"
+)
+
+// Matchers that may be used in place of a value in expected JSON. Multiple
+// matchers in one object must all match, e.g. {"$gte": 18, "$lte": 65}.
+const (
+	// MatchRegex matches strings against a regular expression.
+	MatchRegex = "$regex"
+	// MatchOneOf matches a value equal to any of a list of values.
+	MatchOneOf = "$oneOf"
+	// MatchGte matches numbers greater than or equal to a bound.
+	MatchGte = "$gte"
+	// MatchGt matches numbers greater than a bound.
+	MatchGt = "$gt"
+	// MatchLte matches numbers less than or equal to a bound.
+	MatchLte = "$lte"
+	// MatchLt matches numbers less than a bound.
+	MatchLt = "$lt"
+	// MatchAny matches any value other than null when true.
+	MatchAny = "$any"
+	// MatchAbsent matches a missing field when true, and any present field
+	// when false.
+	MatchAbsent = "$absent"
+)
+
+// matchers are the known matcher keys.
+var matchers = []string{MatchRegex, MatchOneOf, MatchGte, MatchGt, MatchLte, MatchLt, MatchAny, MatchAbsent}
+
+// asMatcher returns v as a matcher object if it is a non-empty object whose
+// keys are all known matchers. Other objects, such as {"$id": "x"}, are
+// compared literally.
+func asMatcher(v any) (map[string]any, bool) {
+	m, ok := v.(map[string]any)
+	if !ok || len(m) == 0 {
+		return nil, false
+	}
+	for key := range m {
+		if !slices.Contains(matchers, key) {
+			return nil, false
+		}
+	}
+	return m, true
+}
+
+// IsMatcher reports whether v is a matcher object, i.e. a non-empty object
+// whose keys are all known matchers.
+func IsMatcher(v any) bool {
+	_, ok := asMatcher(v)
+	return ok
+}
+
+// match checks actual against a matcher object, recording a diff that names
+// the first matcher that failed. present is false if the field is missing.
+func (c *comparer) match(path string, m map[string]any, actual any, present bool) {
+	if want, ok := m[MatchAbsent]; ok {
+		absent, ok := want.(bool)
+		if !ok {
+			c.fail(fmt.Errorf("%s: %s must be true or false", util.PathOrRoot(path), MatchAbsent))
+			return
+		}
+		if absent && present {
+			c.addMatcherDiff(path, types.DiffUnexpected, m, actual, fmt.Sprintf("%s: field is present", MatchAbsent))
+			return
+		}
+		if !absent && !present {
+			c.addMatcherDiff(path, types.DiffMissing, m, nil, fmt.Sprintf("%s: field is missing", MatchAbsent))
+			return
+		}
+	}
+
+	if !present {
+		if _, ok := m[MatchAbsent]; ok {
+			c.pass(path)
+		} else {
+			c.addMatcherDiff(path, types.DiffMissing, m, nil, "field is missing")
+		}
+		return
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(m)) {
+		msg, err := c.check(path, key, m[key], actual)
+		if err != nil {
+			c.fail(fmt.Errorf("%s: %w", util.PathOrRoot(path), err))
+			return
+		}
+		if msg != "" {
+			c.addMatcherDiff(path, types.DiffChanged, m, actual, key+": "+msg)
+			return
+		}
+	}
+
+	c.pass(path)
+}
+
+// check applies a single matcher to actual, returning a message explaining
+// why it failed, or an empty string if it matched. An error is returned if
+// the matcher itself is invalid.
+func (c *comparer) check(path, key string, arg, actual any) (string, error) {
+	switch key {
+	case MatchRegex:
+		pattern, ok := arg.(string)
+		if !ok {
+			return "", fmt.Errorf("%s must be a string", key)
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return "", fmt.Errorf("invalid %s: %w", key, err)
+		}
+		s, ok := actual.(string)
+		if !ok {
+			return fmt.Sprintf("%s is not a string", util.Describe(actual)), nil
+		}
+		if !re.MatchString(s) {
+			return fmt.Sprintf("%s does not match /%s/", util.Describe(s), pattern), nil
+		}
+
+	case MatchOneOf:
+		options, ok := arg.([]any)
+		if !ok {
+			return "", fmt.Errorf("%s must be an array", key)
+		}
+		// Options are scored without the judge, which is asked only about
+		// the closest option
+		var best *comparer
+		var closest any
+		for _, option := range options {
+			sub := c.candidate()
+			sub.compareValues(path, option, actual)
+			if sub.err != nil {
+				continue
+			}
+			if len(sub.diffs) == 0 && !sub.estimated {
+				return "", nil
+			}
+			if best == nil || len(sub.diffs) < len(best.diffs) {
+				best, closest = sub, option
+			}
+		}
+		if best != nil && best.estimated {
+			if c.scoring {
+				c.estimated = true
+			} else {
+				best = c.child()
+				best.compareValues(path, closest, actual)
+				// The error already names the path
+				c.fail(best.err)
+				c.judgements = append(c.judgements, best.judgements...)
+			}
+		}
+		if best != nil && len(best.diffs) == 0 {
+			return "", nil
+		}
+		return fmt.Sprintf("%s is not one of %s", util.Describe(actual), util.Describe(options)), nil
+
+	case MatchGte, MatchGt, MatchLte, MatchLt:
+		bound, ok := arg.(float64)
+		if !ok {
+			return "", fmt.Errorf("%s must be a number", key)
+		}
+		n, ok := actual.(float64)
+		if !ok {
+			return fmt.Sprintf("%s is not a number", util.Describe(actual)), nil
+		}
+		switch {
+		case key == MatchGte && n < bound:
+			return fmt.Sprintf("%g is less than %g", n, bound), nil
+		case key == MatchGt && n <= bound:
+			return fmt.Sprintf("%g is not greater than %g", n, bound), nil
+		case key == MatchLte && n > bound:
+			return fmt.Sprintf("%g is greater than %g", n, bound), nil
+		case key == MatchLt && n >= bound:
+			return fmt.Sprintf("%g is not less than %g", n, bound), nil
+		}
+
+	case MatchAny:
+		want, ok := arg.(bool)
+		if !ok {
+			return "", fmt.Errorf("%s must be true or false", key)
+		}
+		if want && actual == nil {
+			return "value is null", nil
+		}
+	}
+
+	return "", nil
+}
+
+// addMatcherDiff records a failed matcher at path.
+func (c *comparer) addMatcherDiff(path, kind string, m map[string]any, actual any, msg string) {
+	c.record(types.FieldDiff{
+		Path:     util.PathOrRoot(path),
+		Kind:     kind,
+		Expected: m,
+		Actual:   actual,
+		Message:  msg,
+	})
+}
@@ -0,0 +1,116 @@
+package compare
+
+import (
+	"testing"
+
+	"go.carr.sh/litmus/internal/types"
+)
+
+func TestMatchers(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		actual   string
+		// wantKind is the kind of the diff at "v", or empty if it matches.
+		wantKind    string
+		wantMessage string
+	}{
+		{name: "regex", expected: `{"v":{"$regex":"^a+$"}}`, actual: `{"v":"aaa"}`},
+		{name: "regex mismatch", expected: `{"v":{"$regex":"^a+$"}}`, actual: `{"v":"ab"}`, wantKind: types.DiffChanged, wantMessage: `$regex: "ab" does not match /^a+$/`},
+		{name: "regex of a number", expected: `{"v":{"$regex":"1"}}`, actual: `{"v":1}`, wantKind: types.DiffChanged, wantMessage: "$regex: 1 is not a string"},
+		{name: "one of", expected: `{"v":{"$oneOf":["a",1,null]}}`, actual: `{"v":1}`},
+		{name: "one of null", expected: `{"v":{"$oneOf":["a",null]}}`, actual: `{"v":null}`},
+		{name: "one of objects", expected: `{"v":{"$oneOf":[{"a":1},{"a":2}]}}`, actual: `{"v":{"a":2}}`},
+		{name: "none of", expected: `{"v":{"$oneOf":["a","b"]}}`, actual: `{"v":"c"}`, wantKind: types.DiffChanged, wantMessage: `$oneOf: "c" is not one of ["a","b"]`},
+		{name: "gte boundary", expected: `{"v":{"$gte":18}}`, actual: `{"v":18}`},
+		{name: "gte below", expected: `{"v":{"$gte":18}}`, actual: `{"v":17.5}`, wantKind: types.DiffChanged, wantMessage: "$gte: 17.5 is less than 18"},
+		{name: "gt boundary", expected: `{"v":{"$gt":18}}`, actual: `{"v":18}`, wantKind: types.DiffChanged, wantMessage: "$gt: 18 is not greater than 18"},
+		{name: "lte boundary", expected: `{"v":{"$lte":65}}`, actual: `{"v":65}`},
+		{name: "lte above", expected: `{"v":{"$lte":65}}`, actual: `{"v":70}`, wantKind: types.DiffChanged, wantMessage: "$lte: 70 is greater than 65"},
+		{name: "lt boundary", expected: `{"v":{"$lt":65}}`, actual: `{"v":65}`, wantKind: types.DiffChanged, wantMessage: "$lt: 65 is not less than 65"},
+		{name: "range", expected: `{"v":{"$gte":18,"$lte":65}}`, actual: `{"v":40}`},
+		{name: "range of a string", expected: `{"v":{"$gte":18}}`, actual: `{"v":"40"}`, wantKind: types.DiffChanged, wantMessage: `$gte: "40" is not a number`},
+		{name: "any", expected: `{"v":{"$any":true}}`, actual: `{"v":[1]}`},
+		{name: "any null", expected: `{"v":{"$any":true}}`, actual: `{"v":null}`, wantKind: types.DiffChanged, wantMessage: "$any: value is null"},
+		{name: "any missing", expected: `{"v":{"$any":true}}`, actual: `{}`, wantKind: types.DiffMissing, wantMessage: "field is missing"},
+		{name: "absent", expected: `{"v":{"$absent":true}}`, actual: `{}`},
+		{name: "absent present", expected: `{"v":{"$absent":true}}`, actual: `{"v":null}`, wantKind: types.DiffUnexpected, wantMessage: "$absent: field is present"},
+		{name: "present", expected: `{"v":{"$absent":false}}`, actual: `{"v":0}`},
+		{name: "present missing", expected: `{"v":{"$absent":false}}`, actual: `{}`, wantKind: types.DiffMissing, wantMessage: "$absent: field is missing"},
+		{name: "present and matching", expected: `{"v":{"$absent":false,"$regex":"^x"}}`, actual: `{"v":"y"}`, wantKind: types.DiffChanged, wantMessage: `$regex: "y" does not match /^x/`},
+		{name: "in array", expected: `{"v":[{"$gt":0},{"$lt":0}]}`, actual: `{"v":[1,-1]}`},
+		{name: "literal dollar keys", expected: `{"v":{"$id":"x","$ref":"#/a"}}`, actual: `{"v":{"$id":"x","$ref":"#/a"}}`},
+		{name: "literal dollar keys mismatch", expected: `{"v":{"$id":"x"}}`, actual: `{"v":"x"}`, wantKind: types.DiffTypeMismatch},
+		{name: "literal with a matcher key", expected: `{"v":{"$id":"x","$any":true}}`, actual: `{"v":{"$id":"x","$any":true}}`},
+		{name: "empty object", expected: `{"v":{}}`, actual: `{"v":{}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Compare([]byte(tt.expected), []byte(tt.actual), Options{}, nil)
+			if err != nil {
+				t.Fatalf("Compare() error = %v", err)
+			}
+			if tt.wantKind == "" {
+				if len(result.Diffs) != 0 {
+					t.Errorf("Diffs = %+v, want none", result.Diffs)
+				}
+				if result.Score != 1 {
+					t.Errorf("Score = %v, want 1", result.Score)
+				}
+				return
+			}
+			if len(result.Diffs) != 1 {
+				t.Fatalf("Diffs = %+v, want one", result.Diffs)
+			}
+			d := result.Diffs[0]
+			if d.Path != "v" || d.Kind != tt.wantKind || d.Message != tt.wantMessage {
+				t.Errorf("diff = {%s %s %q}, want {v %s %q}", d.Path, d.Kind, d.Message, tt.wantKind, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestInvalidMatchers(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"regex not a string", `{"v":{"$regex":1}}`},
+		{"regex does not compile", `{"v":{"$regex":"("}}`},
+		{"one of not an array", `{"v":{"$oneOf":"a"}}`},
+		{"bound not a number", `{"v":{"$gte":"18"}}`},
+		{"any not a bool", `{"v":{"$any":1}}`},
+		{"absent not a bool", `{"v":{"$absent":"yes"}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Compare([]byte(tt.expected), []byte(`{"v":"a"}`), Options{}, nil); err == nil {
+				t.Error("Compare() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestIsMatcher(t *testing.T) {
+	tests := []struct {
+		name string
+		v    any
+		want bool
+	}{
+		{"matcher", map[string]any{"$gte": 1.0, "$lte": 2.0}, true},
+		{"unknown key", map[string]any{"$id": "x"}, false},
+		{"mixed keys", map[string]any{"$regex": "x", "name": "y"}, false},
+		{"empty object", map[string]any{}, false},
+		{"scalar", "$regex", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsMatcher(tt.v); got != tt.want {
+				t.Errorf("IsMatcher(%v) = %v, want %v", tt.v, got, tt.want)
+			}
+		})
+	}
+}
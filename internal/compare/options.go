@@ -0,0 +1,211 @@
+package compare
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+
+	"go.carr.sh/litmus/internal/util"
+)
+
+// Options configure how expected and actual values are compared. The zero
+// value compares values exactly.
+type Options struct {
+	// Tolerance is the numeric tolerance applied to every number.
+	Tolerance Tolerance `json:"tolerance,omitzero"`
+	// String configures how every string is compared.
+	String StringMatch `json:"string,omitzero"`
+	// Array is how every array is compared: "ordered" (the default),
+	// "unordered" or "set".
+	Array string `json:"array,omitempty"`
+	// Paths override the options for individual fields, keyed by path, e.g.
+	// "items[*].price". An index of [*] matches every element of an array.
+	Paths map[string]PathOptions `json:"paths,omitempty"`
+	// Judge configures the judge model for paths that are judged.
+	Judge JudgeOptions `json:"judge,omitzero"`
+	// IgnoreUnexpected ignores object fields in the response that are not
+	// in the expected value.
+	IgnoreUnexpected bool `json:"ignore_unexpected,omitempty"`
+	// NullAsAbsent treats an object field set to null as if it were absent,
+	// in both the expected value and the response.
+	NullAsAbsent bool `json:"null_as_absent,omitempty"`
+}
+
+// PathOptions configure how the value at a single path is compared.
+type PathOptions struct {
+	// Tolerance replaces the global numeric tolerance for the path.
+	Tolerance *Tolerance `json:"tolerance,omitempty"`
+	// String replaces the global string matching options for the path.
+	String *StringMatch `json:"string,omitempty"`
+	// Array replaces the global array mode for the array at the path.
+	Array string `json:"array,omitempty"`
+	// Weight is the weight of every leaf at or below the path in the field
+	// score. It defaults to 1.
+	Weight *float64 `json:"weight,omitempty"`
+	// Judge has the value at the path scored by a judge model instead of
+	// compared exactly. Options left unset are taken from the global judge
+	// options, and true judges the path with those options alone.
+	Judge *JudgeOptions `json:"judge,omitempty"`
+}
+
+// Tolerance is the amount by which a number may differ from its expected
+// value. A number matches if it is within either tolerance.
+type Tolerance struct {
+	// Abs is the absolute tolerance, e.g. 0.01.
+	Abs float64 `json:"abs,omitempty"`
+	// Rel is the tolerance relative to the expected value, e.g. 0.001 for 0.1%.
+	Rel float64 `json:"rel,omitempty"`
+}
+
+// Allows reports whether actual is within the tolerance of expected: either
+// within Abs of it, or within Rel of it relative to the magnitude of expected.
+func (t Tolerance) Allows(expected, actual float64) bool {
+	diff := math.Abs(actual - expected)
+	return diff <= t.Abs || diff <= t.Rel*math.Abs(expected)
+}
+
+// indexPattern matches array indices in a path.
+var indexPattern = regexp.MustCompile(`\[\d+\]`)
+
+// NormalizePath replaces every array index in a path with [*], so that
+// "items[2].price" becomes "items[*].price".
+func NormalizePath(path string) string {
+	return indexPattern.ReplaceAllString(path, "[*]")
+}
+
+// path returns the options for a path. Options for the exact path take
+// precedence over those for its normalized form.
+func (o Options) path(path string) (PathOptions, bool) {
+	if po, ok := o.Paths[util.PathOrRoot(path)]; ok {
+		return po, true
+	}
+	po, ok := o.Paths[NormalizePath(util.PathOrRoot(path))]
+	return po, ok
+}
+
+// tolerance returns the numeric tolerance for a path.
+func (o Options) tolerance(path string) Tolerance {
+	if po, ok := o.path(path); ok && po.Tolerance != nil {
+		return *po.Tolerance
+	}
+	return o.Tolerance
+}
+
+// stringMatch returns the string matching options for a path.
+func (o Options) stringMatch(path string) StringMatch {
+	if po, ok := o.path(path); ok && po.String != nil {
+		return *po.String
+	}
+	return o.String
+}
+
+// arrayMode returns the array comparison mode for a path.
+func (o Options) arrayMode(path string) string {
+	if po, ok := o.path(path); ok && po.Array != "" {
+		return po.Array
+	}
+	return o.Array
+}
+
+// countsList reports whether the array at path is compared regardless of
+// order, and so is counted for the list metrics.
+func (o Options) countsList(path string) bool {
+	mode := o.arrayMode(path)
+	return mode == ArrayUnordered || mode == ArraySet
+}
+
+// weight returns the score weight of the leaf at path: the weight of the
+// closest enclosing path that has one, or 1.
+func (o Options) weight(path string) float64 {
+	for {
+		if po, ok := o.path(path); ok && po.Weight != nil {
+			return *po.Weight
+		}
+		if path == "" {
+			return 1
+		}
+		path = parentPath(path)
+	}
+}
+
+// parentPath returns the path of the object or array containing path, or an
+// empty string for a top-level field.
+func parentPath(path string) string {
+	if i := strings.LastIndexAny(path, ".["); i >= 0 {
+		return path[:i]
+	}
+	return ""
+}
+
+// Validate checks that the options are well-formed.
+func (o Options) Validate() error {
+	if err := o.Tolerance.validate(); err != nil {
+		return err
+	}
+	if err := o.String.validate(); err != nil {
+		return err
+	}
+	if err := validateArrayMode(o.Array); err != nil {
+		return err
+	}
+	for path, po := range o.Paths {
+		if po.Tolerance != nil {
+			if err := po.Tolerance.validate(); err != nil {
+				return fmt.Errorf("path %q: %w", path, err)
+			}
+		}
+		if po.String != nil {
+			if err := po.String.validate(); err != nil {
+				return fmt.Errorf("path %q: %w", path, err)
+			}
+		}
+		if err := validateArrayMode(po.Array); err != nil {
+			return fmt.Errorf("path %q: %w", path, err)
+		}
+		if po.Weight != nil && *po.Weight < 0 {
+			return fmt.Errorf("path %q: weight must not be negative", path)
+		}
+		if j, ok := o.judge(path); ok {
+			if err := j.validate(); err != nil {
+				return fmt.Errorf("path %q: %w", path, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validateArrayMode checks that an array mode is known.
+func validateArrayMode(mode string) error {
+	switch mode {
+	case "", ArrayOrdered, ArrayUnordered, ArraySet:
+		return nil
+	}
+	return fmt.Errorf("unknown array mode %q (valid: %s, %s, %s)", mode, ArrayOrdered, ArrayUnordered, ArraySet)
+}
+
+// validate checks that the tolerances are not negative.
+func (t Tolerance) validate() error {
+	if t.Abs < 0 || t.Rel < 0 {
+		return fmt.Errorf("tolerance must not be negative")
+	}
+	return nil
+}
+
+// validate checks the normalization form, similarity metric and threshold.
+func (m StringMatch) validate() error {
+	switch m.Normalize {
+	case "", NormalizeNFC, NormalizeNFKC:
+	default:
+		return fmt.Errorf("unknown normalization %q (valid: %s, %s)", m.Normalize, NormalizeNFC, NormalizeNFKC)
+	}
+	switch m.Similarity {
+	case "", SimilarityLevenshtein, SimilarityJaroWinkler:
+	default:
+		return fmt.Errorf("unknown similarity %q (valid: %s, %s)", m.Similarity, SimilarityLevenshtein, SimilarityJaroWinkler)
+	}
+	if m.Threshold < 0 || m.Threshold > 1 {
+		return fmt.Errorf("similarity threshold must be between 0 and 1")
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package compare
+
+import (
+	"math"
+	"testing"
+)
+
+func TestToleranceAllows(t *testing.T) {
+	tests := []struct {
+		name      string
+		tolerance Tolerance
+		expected  float64
+		actual    float64
+		want      bool
+	}{
+		{"exact without tolerance", Tolerance{}, 10, 10, true},
+		{"different without tolerance", Tolerance{}, 10, 10.0001, false},
+		{"within abs", Tolerance{Abs: 0.5}, 10, 10.25, true},
+		{"on abs boundary", Tolerance{Abs: 0.5}, 10, 10.5, true},
+		{"beyond abs", Tolerance{Abs: 0.5}, 10, 10.51, false},
+		{"abs below expected", Tolerance{Abs: 0.5}, 10, 9.5, true},
+		{"within rel", Tolerance{Rel: 0.01}, 200, 201, true},
+		{"on rel boundary", Tolerance{Rel: 0.25}, 200, 250, true},
+		{"beyond rel", Tolerance{Rel: 0.01}, 200, 202.5, false},
+		{"rel of negative expected", Tolerance{Rel: 0.25}, -200, -150, true},
+		{"rel of zero expected", Tolerance{Rel: 0.5}, 0, 0.001, false},
+		{"either tolerance", Tolerance{Abs: 0.1, Rel: 0.01}, 1000, 1009, true},
+		{"abs rescues small values", Tolerance{Abs: 0.1, Rel: 0.01}, 1, 1.05, true},
+		{"neither tolerance", Tolerance{Abs: 0.1, Rel: 0.01}, 1000, 1011, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.tolerance.Allows(tt.expected, tt.actual); got != tt.want {
+				t.Errorf("%+v.Allows(%v, %v) = %v, want %v", tt.tolerance, tt.expected, tt.actual, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareNumbers(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     Options
+		expected string
+		actual   string
+		// wantDelta is the delta of the diff, or nil if the numbers match.
+		wantDelta *float64
+	}{
+		{name: "integer and float", expected: `{"n":3}`, actual: `{"n":3.0}`},
+		{name: "exponent", expected: `{"n":1500}`, actual: `{"n":1.5e3}`},
+		{name: "integer mismatch", expected: `{"n":3}`, actual: `{"n":4}`, wantDelta: ptr(1.0)},
+		{name: "negative delta", expected: `{"n":10}`, actual: `{"n":7.5}`, wantDelta: ptr(-2.5)},
+		{name: "within abs", opts: Options{Tolerance: Tolerance{Abs: 0.01}}, expected: `{"n":9.99}`, actual: `{"n":10}`},
+		{name: "beyond abs", opts: Options{Tolerance: Tolerance{Abs: 0.01}}, expected: `{"n":9.98}`, actual: `{"n":10}`, wantDelta: ptr(0.02)},
+		{name: "within rel", opts: Options{Tolerance: Tolerance{Rel: 0.01}}, expected: `{"n":1000}`, actual: `{"n":1010}`},
+		{name: "beyond rel", opts: Options{Tolerance: Tolerance{Rel: 0.01}}, expected: `{"n":1000}`, actual: `{"n":1011}`, wantDelta: ptr(11.0)},
+		{
+			name:      "path tolerance replaces global",
+			opts:      Options{Tolerance: Tolerance{Abs: 5}, Paths: map[string]PathOptions{"items[*].n": {Tolerance: &Tolerance{Abs: 0.1}}}},
+			expected:  `{"items":[{"n":1}]}`,
+			actual:    `{"items":[{"n":2}]}`,
+			wantDelta: ptr(1.0),
+		},
+		{
+			name:     "path tolerance loosens",
+			opts:     Options{Paths: map[string]PathOptions{"n": {Tolerance: &Tolerance{Rel: 0.1}}}},
+			expected: `{"n":100}`,
+			actual:   `{"n":95}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Compare([]byte(tt.expected), []byte(tt.actual), tt.opts, nil)
+			if err != nil {
+				t.Fatalf("Compare() error = %v", err)
+			}
+			if tt.wantDelta == nil {
+				if len(result.Diffs) != 0 {
+					t.Errorf("Diffs = %+v, want none", result.Diffs)
+				}
+				return
+			}
+			if len(result.Diffs) != 1 {
+				t.Fatalf("Diffs = %+v, want one", result.Diffs)
+			}
+			if d := result.Diffs[0].Delta; d == nil || math.Abs(*d-*tt.wantDelta) > 1e-9 {
+				t.Errorf("Delta = %v, want %v", d, *tt.wantDelta)
+			}
+		})
+	}
+}
+
+func TestValidateTolerance(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr bool
+	}{
+		{"zero", Options{}, false},
+		{"positive", Options{Tolerance: Tolerance{Abs: 0.1, Rel: 0.01}}, false},
+		{"negative abs", Options{Tolerance: Tolerance{Abs: -1}}, true},
+		{"negative rel on a path", Options{Paths: map[string]PathOptions{"n": {Tolerance: &Tolerance{Rel: -0.1}}}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.opts.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// ptr returns a pointer to v.
+func ptr[T any](v T) *T {
+	return &v
+}
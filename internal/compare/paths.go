@@ -0,0 +1,39 @@
+package compare
+
+import (
+	"fmt"
+
+	"go.carr.sh/litmus/internal/util"
+)
+
+// Flatten returns the leaf values of a JSON value keyed by their path, using
+// the same path syntax as field diffs. Empty objects and arrays are leaves.
+func Flatten(v any) map[string]any {
+	leaves := make(map[string]any)
+	flatten("", v, leaves)
+	return leaves
+}
+
+// flatten recursively collects leaf values into leaves.
+func flatten(path string, v any, leaves map[string]any) {
+	switch val := v.(type) {
+	case map[string]any:
+		if len(val) == 0 {
+			leaves[util.PathOrRoot(path)] = val
+			return
+		}
+		for key, child := range val {
+			flatten(util.JoinPath(path, key), child, leaves)
+		}
+	case []any:
+		if len(val) == 0 {
+			leaves[util.PathOrRoot(path)] = val
+			return
+		}
+		for i, child := range val {
+			flatten(fmt.Sprintf("%s[%d]", path, i), child, leaves)
+		}
+	default:
+		leaves[util.PathOrRoot(path)] = val
+	}
+}
@@ -0,0 +1,183 @@
+package compare
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Unicode normalization forms for StringMatch.Normalize.
+const (
+	// NormalizeNFC composes characters canonically, so that "é" written as
+	// "e" plus a combining accent matches the precomposed character.
+	NormalizeNFC = "nfc"
+	// NormalizeNFKC additionally folds compatibility characters such as
+	// ligatures and full-width forms.
+	NormalizeNFKC = "nfkc"
+)
+
+// Similarity metrics for StringMatch.Similarity.
+const (
+	// SimilarityLevenshtein is one minus the edit distance divided by the
+	// length of the longer string.
+	SimilarityLevenshtein = "levenshtein"
+	// SimilarityJaroWinkler is the Jaro-Winkler similarity, which favours
+	// strings with a common prefix.
+	SimilarityJaroWinkler = "jaro_winkler"
+)
+
+// defaultThreshold is the minimum similarity when none is configured.
+const defaultThreshold = 0.9
+
+// StringMatch configures how strings are compared. The zero value compares
+// strings exactly.
+type StringMatch struct {
+	// IgnoreCase compares strings case-insensitively.
+	IgnoreCase bool `json:"ignore_case,omitempty"`
+	// CollapseWhitespace trims strings and collapses runs of whitespace into
+	// a single space.
+	CollapseWhitespace bool `json:"collapse_whitespace,omitempty"`
+	// Normalize applies a Unicode normalization form: "nfc" or "nfkc".
+	Normalize string `json:"normalize,omitempty"`
+	// StripPunctuation removes punctuation, so "Acme Corp." matches "Acme Corp".
+	StripPunctuation bool `json:"strip_punctuation,omitempty"`
+	// Similarity accepts strings whose similarity after normalization is at
+	// least Threshold: "levenshtein" or "jaro_winkler".
+	Similarity string `json:"similarity,omitempty"`
+	// Threshold is the minimum similarity from 0 to 1. It defaults to 0.9.
+	Threshold float64 `json:"threshold,omitempty"`
+}
+
+// normalize applies the configured normalizations to s.
+func (m StringMatch) normalize(s string) string {
+	switch m.Normalize {
+	case NormalizeNFC:
+		s = norm.NFC.String(s)
+	case NormalizeNFKC:
+		s = norm.NFKC.String(s)
+	}
+	if m.IgnoreCase {
+		s = strings.ToLower(s)
+	}
+	if m.StripPunctuation {
+		s = strings.Map(func(r rune) rune {
+			if unicode.IsPunct(r) {
+				return -1
+			}
+			return r
+		}, s)
+	}
+	if m.CollapseWhitespace {
+		s = strings.Join(strings.Fields(s), " ")
+	}
+	return s
+}
+
+// Match reports whether actual matches expected. If a similarity metric is
+// configured, the similarity of the normalized strings is also returned.
+func (m StringMatch) Match(expected, actual string) (bool, *float64) {
+	expected, actual = m.normalize(expected), m.normalize(actual)
+	if m.Similarity == "" {
+		return expected == actual, nil
+	}
+
+	var sim float64
+	switch m.Similarity {
+	case SimilarityLevenshtein:
+		sim = levenshteinSimilarity(expected, actual)
+	case SimilarityJaroWinkler:
+		sim = jaroWinkler(expected, actual)
+	}
+
+	threshold := m.Threshold
+	if threshold == 0 {
+		threshold = defaultThreshold
+	}
+	return sim >= threshold, &sim
+}
+
+// levenshteinSimilarity returns one minus the Levenshtein distance between a
+// and b divided by the length of the longer string, in runes.
+func levenshteinSimilarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 1
+	}
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return 1 - float64(prev[len(rb)])/float64(longest)
+}
+
+// jaroWinkler returns the Jaro-Winkler similarity of a and b, boosting the
+// Jaro similarity for a common prefix of up to four runes.
+func jaroWinkler(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 && len(rb) == 0 {
+		return 1
+	}
+	if len(ra) == 0 || len(rb) == 0 {
+		return 0
+	}
+
+	window := max(max(len(ra), len(rb))/2-1, 0)
+	matchedA := make([]bool, len(ra))
+	matchedB := make([]bool, len(rb))
+
+	matches := 0
+	for i := range ra {
+		lo, hi := max(0, i-window), min(len(rb), i+window+1)
+		for j := lo; j < hi; j++ {
+			if !matchedB[j] && ra[i] == rb[j] {
+				matchedA[i], matchedB[j] = true, true
+				matches++
+				break
+			}
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	// Count matched runes that appear in a different order
+	transpositions := 0
+	j := 0
+	for i := range ra {
+		if !matchedA[i] {
+			continue
+		}
+		for !matchedB[j] {
+			j++
+		}
+		if ra[i] != rb[j] {
+			transpositions++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(ra)) + m/float64(len(rb)) + (m-float64(transpositions)/2)/m) / 3
+
+	prefix := 0
+	for prefix < min(4, len(ra), len(rb)) && ra[prefix] == rb[prefix] {
+		prefix++
+	}
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
@@ -0,0 +1,81 @@
+package compare
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLevenshteinSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"", "", 1},
+		{"abc", "", 0},
+		{"", "abc", 0},
+		{"same", "same", 1},
+		{"kitten", "sitting", 1 - 3.0/7},
+		{"flaw", "lawn", 0.5},
+		{"abc", "xyz", 0},
+		// Lengths are counted in runes, not bytes
+		{"café", "cafe", 0.75},
+	}
+
+	for _, tt := range tests {
+		if got := levenshteinSimilarity(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("levenshteinSimilarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+		if got := levenshteinSimilarity(tt.b, tt.a); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("levenshteinSimilarity(%q, %q) = %v, want %v", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
+
+func TestJaroWinkler(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"", "", 1},
+		{"abc", "", 0},
+		{"same", "same", 1},
+		{"abc", "xyz", 0},
+		// Reference values from Winkler's paper
+		{"MARTHA", "MARHTA", 0.9611},
+		{"DWAYNE", "DUANE", 0.84},
+		{"DIXON", "DICKSONX", 0.8133},
+	}
+
+	for _, tt := range tests {
+		if got := jaroWinkler(tt.a, tt.b); math.Abs(got-tt.want) > 1e-4 {
+			t.Errorf("jaroWinkler(%q, %q) = %.4f, want %.4f", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestStringMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		match    StringMatch
+		expected string
+		actual   string
+		want     bool
+	}{
+		{"exact", StringMatch{}, "Acme", "acme", false},
+		{"ignore case", StringMatch{IgnoreCase: true}, "Acme", "ACME", true},
+		{"collapse whitespace", StringMatch{CollapseWhitespace: true}, "Acme  Corp", " Acme Corp ", true},
+		{"strip punctuation", StringMatch{StripPunctuation: true}, "Acme Corp.", "Acme Corp", true},
+		{"nfc", StringMatch{Normalize: NormalizeNFC}, "café", "café", true},
+		{"nfkc", StringMatch{Normalize: NormalizeNFKC}, "ﬁle", "file", true},
+		{"similar", StringMatch{Similarity: SimilarityLevenshtein, Threshold: 0.8}, "Acme Corporation", "Acme Corporatoin", true},
+		{"not similar enough", StringMatch{Similarity: SimilarityLevenshtein}, "Acme", "Apex", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := tt.match.Match(tt.expected, tt.actual); got != tt.want {
+				t.Errorf("Match(%q, %q) = %v, want %v", tt.expected, tt.actual, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+// Package config loads the optional litmus configuration file.
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"go.carr.sh/litmus/internal/compare"
+	"go.carr.sh/litmus/internal/types"
+)
+
+// Provider types that can be declared in the configuration file.
+const (
+	// ProviderOpenAI is an OpenAI-compatible chat completions API.
+	ProviderOpenAI = "openai"
+	// ProviderAnthropic is the Anthropic Messages API.
+	ProviderAnthropic = "anthropic"
+	// ProviderGemini is the Google Gemini API.
+	ProviderGemini = "gemini"
+	// ProviderOllama is the native Ollama API.
+	ProviderOllama = "ollama"
+)
+
+// Config is the contents of a litmus configuration file.
+type Config struct {
+	// Providers declares additional backends, keyed by the model prefix used
+	// to select them (e.g. "vllm" for "vllm:meta-llama/Llama-3.1-8B-Instruct").
+	Providers map[string]ProviderConfig `json:"providers,omitempty"`
+	// Params are the default sampling parameters for every model.
+	Params types.Params `json:"params,omitzero"`
+	// ModelParams override Params for individual models, keyed by the model
+	// name as passed to --model.
+	ModelParams map[string]types.Params `json:"model_params,omitempty"`
+	// Gates are the quality gates every model must pass.
+	Gates types.Gates `json:"gates,omitzero"`
+	// ModelGates override Gates for individual models, keyed by the model
+	// name as passed to --model.
+	ModelGates map[string]types.Gates `json:"model_gates,omitempty"`
+	// Compare configures how responses are compared with expected values.
+	Compare compare.Options `json:"compare,omitzero"`
+	// Columns maps the columns of CSV and TSV test files to test case fields.
+	Columns types.Columns `json:"columns,omitzero"`
+}
+
+// ProviderConfig configures a single backend.
+type ProviderConfig struct {
+	// Type is the kind of backend: "openai", "anthropic", "gemini" or "ollama".
+	Type string `json:"type"`
+	// BaseURL is the base URL of the API, e.g. "http://localhost:8000/v1".
+	BaseURL string `json:"base_url,omitempty"`
+	// APIKeyEnv is the environment variable holding the API key. It defaults
+	// to the provider's standard variable (e.g. ANTHROPIC_API_KEY), except for
+	// "openai" backends, which send requests without authentication if unset.
+	// It is ignored for "ollama" backends.
+	APIKeyEnv string `json:"api_key_env,omitempty"`
+	// Headers are extra HTTP headers sent with every request.
+	Headers map[string]string `json:"headers,omitempty"`
+	// ResponseFormat selects how the schema is sent to "openai" backends:
+	// "json_schema", "json_object" or "none".
+	ResponseFormat string `json:"response_format,omitempty"`
+}
+
+// Load reads and parses a configuration file.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	if err := cfg.Compare.Validate(); err != nil {
+		return nil, fmt.Errorf("config: compare: %w", err)
+	}
+
+	for name, p := range cfg.Providers {
+		if p.Type == "" {
+			return nil, fmt.Errorf("config: provider %q: type is required", name)
+		}
+	}
+
+	return &cfg, nil
+}
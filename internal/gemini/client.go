@@ -0,0 +1,270 @@
+// Package gemini provides an HTTP client for the Google Gemini API.
+package gemini
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.carr.sh/litmus/internal/provider"
+)
+
+const (
+	defaultBaseURL    = "https://generativelanguage.googleapis.com/v1beta"
+	defaultMaxRetries = 3
+	defaultRetryDelay = time.Second
+	defaultTimeout    = 120 * time.Second
+)
+
+// Client is an HTTP client for the Gemini generateContent API.
+type Client struct {
+	httpClient *http.Client
+	apiKey     string
+	baseURL    string
+	headers    map[string]string
+	maxRetries int
+	retryDelay time.Duration
+}
+
+var _ provider.Provider = (*Client)(nil)
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithBaseURL sets a custom base URL for the API.
+func WithBaseURL(url string) Option {
+	return func(c *Client) {
+		c.baseURL = strings.TrimSuffix(url, "/")
+	}
+}
+
+// WithHTTPClient sets a custom HTTP client.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithRetry configures retry behavior.
+func WithRetry(maxRetries int, retryDelay time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.retryDelay = retryDelay
+	}
+}
+
+// WithTimeout sets the HTTP client timeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.httpClient.Timeout = timeout
+	}
+}
+
+// WithHeaders sets extra HTTP headers sent with every request.
+func WithHeaders(headers map[string]string) Option {
+	return func(c *Client) {
+		for k, v := range headers {
+			c.headers[k] = v
+		}
+	}
+}
+
+// NewClient creates a new Gemini API client.
+func NewClient(apiKey string, opts ...Option) *Client {
+	c := &Client{
+		httpClient: &http.Client{Timeout: defaultTimeout},
+		apiKey:     apiKey,
+		baseURL:    defaultBaseURL,
+		headers:    make(map[string]string),
+		maxRetries: defaultMaxRetries,
+		retryDelay: defaultRetryDelay,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Part is a single piece of message content.
+type Part struct {
+	// Text is the plain text content of the part.
+	Text string `json:"text"`
+}
+
+// Content is a message made up of parts.
+type Content struct {
+	// Role is the role of the message, either "user" or "model".
+	Role string `json:"role,omitempty"`
+	// Parts are the parts of the message.
+	Parts []Part `json:"parts"`
+}
+
+// GenerationConfig configures generation, including structured output.
+type GenerationConfig struct {
+	// ResponseMIMEType is the MIME type of the response, "application/json"
+	// for structured output.
+	ResponseMIMEType string `json:"responseMimeType,omitempty"`
+	// ResponseSchema is the schema the response must conform to.
+	ResponseSchema map[string]any `json:"responseSchema,omitempty"`
+	// Temperature controls randomness.
+	Temperature *float64 `json:"temperature,omitempty"`
+	// TopP is the nucleus sampling probability mass.
+	TopP *float64 `json:"topP,omitempty"`
+	// Seed requests deterministic sampling.
+	Seed *int `json:"seed,omitempty"`
+	// MaxOutputTokens is the maximum number of tokens to generate.
+	MaxOutputTokens *int `json:"maxOutputTokens,omitempty"`
+	// StopSequences is a list of sequences that end generation.
+	StopSequences []string `json:"stopSequences,omitempty"`
+	// FrequencyPenalty penalises frequently repeated tokens.
+	FrequencyPenalty *float64 `json:"frequencyPenalty,omitempty"`
+	// PresencePenalty penalises tokens that have already appeared.
+	PresencePenalty *float64 `json:"presencePenalty,omitempty"`
+}
+
+// GenerateContentRequest represents a generateContent request.
+type GenerateContentRequest struct {
+	// SystemInstruction is the system prompt.
+	SystemInstruction *Content `json:"systemInstruction,omitempty"`
+	// Contents is the conversation.
+	Contents []Content `json:"contents"`
+	// GenerationConfig configures generation.
+	GenerationConfig GenerationConfig `json:"generationConfig"`
+}
+
+// Candidate is a single generated response.
+type Candidate struct {
+	// Content is the generated content.
+	Content Content `json:"content"`
+	// FinishReason is the reason generation stopped.
+	FinishReason string `json:"finishReason"`
+}
+
+// UsageMetadata represents token usage information.
+type UsageMetadata struct {
+	// PromptTokenCount is the number of tokens in the prompt.
+	PromptTokenCount int `json:"promptTokenCount"`
+	// CandidatesTokenCount is the number of tokens in the generated response.
+	CandidatesTokenCount int `json:"candidatesTokenCount"`
+	// ThoughtsTokenCount is the number of tokens used for thinking.
+	ThoughtsTokenCount int `json:"thoughtsTokenCount"`
+	// CachedContentTokenCount is the number of prompt tokens read from the cache.
+	CachedContentTokenCount int `json:"cachedContentTokenCount"`
+}
+
+// GenerateContentResponse represents a generateContent response.
+type GenerateContentResponse struct {
+	// Candidates is the list of generated responses.
+	Candidates []Candidate `json:"candidates"`
+	// UsageMetadata is the token usage for the request.
+	UsageMetadata UsageMetadata `json:"usageMetadata"`
+	// ModelVersion is the model version that completed the request.
+	ModelVersion string `json:"modelVersion"`
+}
+
+// Complete sends a generateContent request with a response schema converted
+// from the litmus JSON schema. Keywords that had to be dropped during the
+// conversion are returned as warnings.
+func (c *Client) Complete(ctx context.Context, r provider.Request) (*provider.CompletionResult, error) {
+	schema, warnings, err := ConvertSchema(r.Schema)
+	if err != nil {
+		return nil, err
+	}
+
+	req := GenerateContentRequest{
+		Contents: []Content{
+			{Role: "user", Parts: []Part{{Text: r.UserInput}}},
+		},
+		GenerationConfig: GenerationConfig{
+			ResponseMIMEType: "application/json",
+			ResponseSchema:   schema,
+			Temperature:      r.Params.Temperature,
+			TopP:             r.Params.TopP,
+			Seed:             r.Params.Seed,
+			MaxOutputTokens:  r.Params.MaxTokens,
+			StopSequences:    r.Params.Stop,
+			FrequencyPenalty: r.Params.FrequencyPenalty,
+			PresencePenalty:  r.Params.PresencePenalty,
+		},
+	}
+	if r.SystemPrompt != "" {
+		req.SystemInstruction = &Content{Parts: []Part{{Text: r.SystemPrompt}}}
+	}
+
+	result, err := provider.Retry(ctx, c.maxRetries, c.retryDelay, func() (*provider.CompletionResult, error) {
+		return c.doRequest(ctx, r.Model, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Warnings = warnings
+	return result, nil
+}
+
+func (c *Client) doRequest(ctx context.Context, model string, genReq GenerateContentRequest) (*provider.CompletionResult, error) {
+	body, err := json.Marshal(genReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	endpoint := c.baseURL + "/models/" + url.PathEscape(model) + ":generateContent"
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-goog-api-key", c.apiKey)
+	for k, v := range c.headers {
+		req.Header.Set(k, v)
+	}
+
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	latency := time.Since(start)
+
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(respBody))
+	}
+
+	var genResp GenerateContentResponse
+	if err := json.Unmarshal(respBody, &genResp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	if len(genResp.Candidates) == 0 {
+		return nil, fmt.Errorf("no candidates in response")
+	}
+
+	var content strings.Builder
+	for _, part := range genResp.Candidates[0].Content.Parts {
+		content.WriteString(part.Text)
+	}
+
+	usage := genResp.UsageMetadata
+	return &provider.CompletionResult{
+		Response:        json.RawMessage(content.String()),
+		Provider:        "Google",
+		TokensIn:        usage.PromptTokenCount,
+		TokensOut:       usage.CandidatesTokenCount + usage.ThoughtsTokenCount,
+		CacheReadTokens: usage.CachedContentTokenCount,
+		Latency:         latency,
+	}, nil
+}
@@ -0,0 +1,169 @@
+package gemini
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"go.carr.sh/litmus/internal/jsonschema"
+)
+
+// supportedKeywords are the JSON Schema keywords that map directly onto the
+// Gemini Schema object.
+var supportedKeywords = []string{
+	"type", "format", "title", "description", "nullable", "enum",
+	"properties", "required", "minProperties", "maxProperties",
+	"items", "minItems", "maxItems",
+	"minLength", "maxLength", "pattern",
+	"minimum", "maximum", "anyOf", "propertyOrdering", "default", "example",
+}
+
+// ignoredKeywords are dropped without a warning because they carry no
+// meaning for generation.
+var ignoredKeywords = []string{"$schema", "$id", "$comment", "$defs", "definitions"}
+
+// converter translates JSON Schema into Gemini's OpenAPI-style schema dialect.
+type converter struct {
+	root     map[string]any
+	warnings []string
+}
+
+// ConvertSchema converts a JSON schema into the Gemini responseSchema form.
+// Keywords that Gemini does not support are dropped and reported as warnings.
+func ConvertSchema(schema json.RawMessage) (map[string]any, []string, error) {
+	var root map[string]any
+	if err := json.Unmarshal(schema, &root); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+
+	c := &converter{root: root}
+	return c.convert("#", root, 0), c.warnings, nil
+}
+
+// convert converts a single schema node.
+func (c *converter) convert(path string, node map[string]any, depth int) map[string]any {
+	if ref, ok := node["$ref"].(string); ok {
+		// References are inlined to a limited depth, as Gemini schemas
+		// cannot express recursion
+		target := jsonschema.ResolveRef(c.root, ref)
+		if target == nil || depth >= jsonschema.MaxRefDepth {
+			c.warn(path, "$ref", fmt.Sprintf("could not inline %q", ref))
+			return map[string]any{}
+		}
+		return c.convert(path, target, depth+1)
+	}
+
+	out := make(map[string]any)
+
+	for _, key := range slices.Sorted(maps.Keys(node)) {
+		value := node[key]
+		switch key {
+		case "type":
+			c.convertType(path, value, out)
+		case "properties":
+			props, _ := value.(map[string]any)
+			converted := make(map[string]any, len(props))
+			for _, name := range slices.Sorted(maps.Keys(props)) {
+				if propNode, ok := props[name].(map[string]any); ok {
+					converted[name] = c.convert(path+"/properties/"+name, propNode, depth)
+				}
+			}
+			out["properties"] = converted
+		case "items":
+			if itemNode, ok := value.(map[string]any); ok {
+				out["items"] = c.convert(path+"/items", itemNode, depth)
+			} else {
+				c.warn(path, key, "only a single items schema is supported")
+			}
+		case "anyOf", "oneOf":
+			out["anyOf"] = c.convertList(path+"/"+key, value, depth)
+			if key == "oneOf" {
+				c.warn(path, key, "converted to anyOf")
+			}
+		case "const":
+			if s, ok := value.(string); ok {
+				out["enum"] = []any{s}
+			} else {
+				c.warn(path, key, "only string constants are supported")
+			}
+		case "enum":
+			values, _ := value.([]any)
+			if slices.ContainsFunc(values, func(v any) bool { _, ok := v.(string); return !ok }) {
+				c.warn(path, key, "only string enums are supported")
+				continue
+			}
+			out["enum"] = values
+		default:
+			if slices.Contains(ignoredKeywords, key) {
+				continue
+			}
+			if !slices.Contains(supportedKeywords, key) {
+				c.warn(path, key, "")
+				continue
+			}
+			out[key] = value
+		}
+	}
+
+	return out
+}
+
+// convertType maps JSON Schema types onto Gemini types. A nullable type union
+// such as ["string", "null"] becomes a nullable single type, and any other
+// union becomes an anyOf.
+func (c *converter) convertType(path string, value any, out map[string]any) {
+	var types []string
+	switch v := value.(type) {
+	case string:
+		types = []string{v}
+	case []any:
+		for _, t := range v {
+			if s, ok := t.(string); ok {
+				types = append(types, s)
+			}
+		}
+	}
+
+	if i := slices.Index(types, "null"); i >= 0 {
+		types = slices.Delete(types, i, i+1)
+		out["nullable"] = true
+	}
+
+	switch len(types) {
+	case 0:
+		c.warn(path, "type", "a schema cannot only allow null")
+	case 1:
+		out["type"] = strings.ToUpper(types[0])
+	default:
+		anyOf := make([]any, len(types))
+		for i, t := range types {
+			anyOf[i] = map[string]any{"type": strings.ToUpper(t)}
+		}
+		out["anyOf"] = anyOf
+	}
+}
+
+// convertList converts a list of subschemas.
+func (c *converter) convertList(path string, value any, depth int) []any {
+	list, _ := value.([]any)
+	out := make([]any, 0, len(list))
+	for i, item := range list {
+		if node, ok := item.(map[string]any); ok {
+			out = append(out, c.convert(fmt.Sprintf("%s/%d", path, i), node, depth))
+		}
+	}
+	return out
+}
+
+// warn records a dropped or rewritten keyword, ignoring duplicates.
+func (c *converter) warn(path, keyword, detail string) {
+	msg := fmt.Sprintf("gemini: dropped unsupported keyword %q at %s", keyword, path)
+	if detail != "" {
+		msg = fmt.Sprintf("gemini: keyword %q at %s: %s", keyword, path, detail)
+	}
+	if !slices.Contains(c.warnings, msg) {
+		c.warnings = append(c.warnings, msg)
+	}
+}
@@ -0,0 +1,123 @@
+package gemini
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestConvertSchema(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		// want is the converted schema.
+		want string
+		// wantWarnings are the warnings, in order.
+		wantWarnings []string
+	}{
+		{
+			name:   "supported keywords",
+			schema: `{"type":"object","properties":{"name":{"type":"string","minLength":1}},"required":["name"]}`,
+			want:   `{"type":"OBJECT","properties":{"name":{"type":"STRING","minLength":1}},"required":["name"]}`,
+		},
+		{
+			name:   "nullable type",
+			schema: `{"type":["string","null"]}`,
+			want:   `{"type":"STRING","nullable":true}`,
+		},
+		{
+			name:   "type union",
+			schema: `{"type":["string","integer"]}`,
+			want:   `{"anyOf":[{"type":"STRING"},{"type":"INTEGER"}]}`,
+		},
+		{
+			name:         "null only",
+			schema:       `{"type":"null"}`,
+			want:         `{"nullable":true}`,
+			wantWarnings: []string{`gemini: keyword "type" at #: a schema cannot only allow null`},
+		},
+		{
+			name:   "anyOf",
+			schema: `{"anyOf":[{"type":"string"},{"type":"number"}]}`,
+			want:   `{"anyOf":[{"type":"STRING"},{"type":"NUMBER"}]}`,
+		},
+		{
+			name:         "oneOf becomes anyOf",
+			schema:       `{"oneOf":[{"type":"string"},{"type":"number"}]}`,
+			want:         `{"anyOf":[{"type":"STRING"},{"type":"NUMBER"}]}`,
+			wantWarnings: []string{`gemini: keyword "oneOf" at #: converted to anyOf`},
+		},
+		{
+			name:   "ref is inlined",
+			schema: `{"$defs":{"name":{"type":"string"}},"type":"object","properties":{"first":{"$ref":"#/$defs/name"},"last":{"$ref":"#/$defs/name"}}}`,
+			want:   `{"type":"OBJECT","properties":{"first":{"type":"STRING"},"last":{"type":"STRING"}}}`,
+		},
+		{
+			name:         "unresolved ref",
+			schema:       `{"type":"object","properties":{"id":{"$ref":"#/$defs/missing"}}}`,
+			want:         `{"type":"OBJECT","properties":{"id":{}}}`,
+			wantWarnings: []string{`gemini: keyword "$ref" at #/properties/id: could not inline "#/$defs/missing"`},
+		},
+		{
+			name:   "recursive ref stops at the depth limit",
+			schema: `{"$defs":{"node":{"type":"object","properties":{"next":{"$ref":"#/$defs/node"}}}},"$ref":"#/$defs/node"}`,
+			want: `{"type":"OBJECT","properties":{"next":{"type":"OBJECT","properties":{"next":{"type":"OBJECT","properties":{"next":{"type":"OBJECT","properties":{"next":` +
+				`{"type":"OBJECT","properties":{"next":{"type":"OBJECT","properties":{"next":{"type":"OBJECT","properties":{"next":{"type":"OBJECT","properties":{"next":{}}}}}}}}}}}}}}}}}`,
+			wantWarnings: []string{`gemini: keyword "$ref" at #/properties/next/properties/next/properties/next/properties/next/properties/next/properties/next/properties/next/properties/next: could not inline "#/$defs/node"`},
+		},
+		{
+			name:   "string const",
+			schema: `{"const":"fixed"}`,
+			want:   `{"enum":["fixed"]}`,
+		},
+		{
+			name:         "non-string enum",
+			schema:       `{"type":"integer","enum":[1,2]}`,
+			want:         `{"type":"INTEGER"}`,
+			wantWarnings: []string{`gemini: keyword "enum" at #: only string enums are supported`},
+		},
+		{
+			name:   "dropped keywords",
+			schema: `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","additionalProperties":false,"properties":{"tags":{"type":"array","uniqueItems":true}}}`,
+			want:   `{"type":"OBJECT","properties":{"tags":{"type":"ARRAY"}}}`,
+			wantWarnings: []string{
+				`gemini: dropped unsupported keyword "additionalProperties" at #`,
+				`gemini: dropped unsupported keyword "uniqueItems" at #/properties/tags`,
+			},
+		},
+		{
+			name:         "tuple items",
+			schema:       `{"type":"array","items":[{"type":"string"}]}`,
+			want:         `{"type":"ARRAY"}`,
+			wantWarnings: []string{`gemini: keyword "items" at #: only a single items schema is supported`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, warnings, err := ConvertSchema(json.RawMessage(tt.schema))
+			if err != nil {
+				t.Fatalf("ConvertSchema() error = %v", err)
+			}
+
+			var want map[string]any
+			if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+				t.Fatalf("invalid expected schema: %v", err)
+			}
+			gb, _ := json.Marshal(got)
+			wb, _ := json.Marshal(want)
+			if string(gb) != string(wb) {
+				t.Errorf("ConvertSchema() = %s, want %s", gb, wb)
+			}
+			if !slices.Equal(warnings, tt.wantWarnings) {
+				t.Errorf("warnings = %q, want %q", warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestConvertSchemaInvalid(t *testing.T) {
+	if _, _, err := ConvertSchema(json.RawMessage(`[`)); err == nil {
+		t.Error("ConvertSchema() error = nil, want an error for invalid JSON")
+	}
+}
@@ -0,0 +1,41 @@
+// Package jsonschema inspects JSON schemas.
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"go.carr.sh/litmus/internal/util"
+)
+
+// Enums returns the allowed values of every enum field in a schema, keyed by
+// path in the syntax of field diffs with array indices written as [*]. The
+// enum of the root value is keyed by "(root)". Where a field has several
+// enums, such as in the branches of an anyOf, their values are combined in
+// order of appearance.
+func Enums(schema json.RawMessage) (map[string][]any, error) {
+	var root map[string]any
+	if err := json.Unmarshal(schema, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+
+	enums := make(map[string][]any)
+	walkFields(root, root, "", func(node map[string]any, path string) {
+		values, _ := node["enum"].([]any)
+		key := util.PathOrRoot(path)
+		for _, v := range values {
+			if !slices.ContainsFunc(enums[key], func(e any) bool { return equal(e, v) }) {
+				enums[key] = append(enums[key], v)
+			}
+		}
+	}, 0)
+	return enums, nil
+}
+
+// equal reports whether two JSON values are equal.
+func equal(a, b any) bool {
+	x, _ := json.Marshal(a)
+	y, _ := json.Marshal(b)
+	return string(x) == string(y)
+}
@@ -0,0 +1,38 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestEnums(t *testing.T) {
+	schema := `{
+  "$defs": {"status": {"enum": ["active", "closed"]}},
+  "enum": [{"a": 1}],
+  "properties": {
+    "status": {"$ref": "#/$defs/status"},
+    "level": {"anyOf": [{"enum": [1, 2]}, {"enum": [2, 3, null]}]},
+    "items": {"type": "array", "items": {"enum": ["x"]}},
+    "name": {"type": "string"}
+  }
+}`
+
+	got, err := Enums(json.RawMessage(schema))
+	if err != nil {
+		t.Fatalf("Enums() error = %v", err)
+	}
+	want := map[string]string{
+		"(root)":   `[{"a":1}]`,
+		"status":   `["active","closed"]`,
+		"level":    `[1,2,3,null]`,
+		"items[*]": `["x"]`,
+	}
+	if len(got) != len(want) {
+		t.Errorf("Enums() = %v, want %d fields", got, len(want))
+	}
+	for path, values := range want {
+		if b, _ := json.Marshal(got[path]); string(b) != values {
+			t.Errorf("Enums()[%q] = %s, want %s", path, b, values)
+		}
+	}
+}
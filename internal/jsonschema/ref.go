@@ -0,0 +1,73 @@
+package jsonschema
+
+import (
+	"strings"
+
+	"go.carr.sh/litmus/internal/util"
+)
+
+// MaxRefDepth limits how deeply $ref references are followed, so that
+// recursive schemas terminate.
+const MaxRefDepth = 8
+
+// ResolveRef resolves a local reference such as "#/$defs/address" against the
+// root of a schema, returning nil if it cannot be resolved.
+func ResolveRef(root map[string]any, ref string) map[string]any {
+	if ref == "#" {
+		return root
+	}
+	pointer, ok := strings.CutPrefix(ref, "#/")
+	if !ok {
+		return nil
+	}
+
+	var node any = root
+	for _, part := range strings.Split(pointer, "/") {
+		part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+		m, ok := node.(map[string]any)
+		if !ok {
+			return nil
+		}
+		node = m[part]
+	}
+
+	result, _ := node.(map[string]any)
+	return result
+}
+
+// walkFields walks the schema nodes that describe fields, from node down
+// through references, properties, items and the branches of anyOf, oneOf and
+// allOf, calling visit with each node and the path of its field in the syntax
+// of field diffs, with array indices written as [*]. Branches and referenced
+// schemas describe the same field as the node that holds them. depth counts
+// the references followed so far.
+func walkFields(root, node map[string]any, path string, visit func(node map[string]any, path string), depth int) {
+	if ref, ok := node["$ref"].(string); ok {
+		if target := ResolveRef(root, ref); target != nil && depth < MaxRefDepth {
+			walkFields(root, target, path, visit, depth+1)
+		}
+	}
+
+	visit(node, path)
+
+	if props, ok := node["properties"].(map[string]any); ok {
+		for name, prop := range props {
+			if child, ok := prop.(map[string]any); ok {
+				walkFields(root, child, util.JoinPath(path, name), visit, depth)
+			}
+		}
+	}
+
+	if items, ok := node["items"].(map[string]any); ok {
+		walkFields(root, items, path+"[*]", visit, depth)
+	}
+
+	for _, key := range []string{"anyOf", "oneOf", "allOf"} {
+		branches, _ := node[key].([]any)
+		for _, branch := range branches {
+			if child, ok := branch.(map[string]any); ok {
+				walkFields(root, child, path, visit, depth)
+			}
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestWalkFields(t *testing.T) {
+	schema := `{
+  "$defs": {
+    "tag": {"type": "string"},
+    "node": {"type": "object", "properties": {"next": {"$ref": "#/$defs/node"}}}
+  },
+  "type": "object",
+  "properties": {
+    "tags": {"type": "array", "items": {"$ref": "#/$defs/tag"}},
+    "owner": {"anyOf": [{"type": "object", "properties": {"name": {"type": "string"}}}, {"type": "null"}]},
+    "a/b": {"type": "string"},
+    "list": {"$ref": "#/$defs/node"}
+  }
+}`
+	var root map[string]any
+	if err := json.Unmarshal([]byte(schema), &root); err != nil {
+		t.Fatal(err)
+	}
+
+	visits := make(map[string]int)
+	walkFields(root, root, "", func(node map[string]any, path string) {
+		visits[path]++
+	}, 0)
+
+	want := map[string]int{
+		"":           1,
+		"tags":       1,
+		"tags[*]":    2, // the items node and the tag it references
+		"owner":      3, // the anyOf node and its two branches
+		"owner.name": 1,
+		"a/b":        1,
+		"list":       2,
+	}
+	for path, n := range want {
+		if visits[path] != n {
+			t.Errorf("visits[%q] = %d, want %d", path, visits[path], n)
+		}
+	}
+
+	// The recursive reference is followed MaxRefDepth times
+	deepest := "list" + strings.Repeat(".next", MaxRefDepth)
+	if visits[deepest] != 1 {
+		t.Errorf("visits[%q] = %d, want 1", deepest, visits[deepest])
+	}
+	if _, ok := visits[deepest+".next"]; ok {
+		t.Errorf("visited %q beyond MaxRefDepth", deepest+".next")
+	}
+	if len(visits) != len(want)+MaxRefDepth {
+		t.Errorf("visited %d paths, want %d", len(visits), len(want)+MaxRefDepth)
+	}
+}
@@ -0,0 +1,395 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go.carr.sh/litmus/internal/util"
+)
+
+// Dialect is the subset of JSON schema accepted by a provider's structured
+// output mode.
+type Dialect struct {
+	// Name identifies the dialect in messages, e.g. "openai".
+	Name string
+	// Strict requires the root to be an object, and every object to list all
+	// of its properties as required and set additionalProperties to false.
+	Strict bool
+	// Unsupported are the keywords the provider rejects.
+	Unsupported []string
+}
+
+// OpenAI is the dialect of OpenAI's strict structured outputs, which
+// OpenRouter also uses.
+var OpenAI = Dialect{
+	Name:   "openai",
+	Strict: true,
+	Unsupported: []string{
+		"format", "minLength", "maxLength", "pattern",
+		"minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum", "multipleOf",
+		"patternProperties", "unevaluatedProperties", "propertyNames", "minProperties", "maxProperties",
+		"unevaluatedItems", "contains", "minContains", "maxContains", "minItems", "maxItems", "uniqueItems",
+		"allOf", "not", "if", "then", "else", "dependentRequired", "dependentSchemas",
+	},
+}
+
+// Dialects are the known dialects, by name. Only OpenAI's strict mode is
+// known: Anthropic and Gemini models are not checked, and Gemini schemas are
+// converted by the gemini package instead.
+var Dialects = map[string]Dialect{
+	OpenAI.Name: OpenAI,
+}
+
+// applicators are keywords holding subschemas. Fix cannot drop them without
+// changing which values the schema allows, so it leaves them in place.
+var applicators = []string{"allOf", "not", "if", "then", "else", "dependentSchemas"}
+
+// Issue is a problem found at a location in a schema.
+type Issue struct {
+	// Pointer is the JSON pointer of the schema node, e.g.
+	// "/properties/address". The root node is "".
+	Pointer string
+	// Keyword is the keyword the issue concerns, e.g. "required".
+	Keyword string
+	// Message describes the problem.
+	Message string
+}
+
+// Check returns the ways in which a schema is incompatible with a dialect.
+// Issues are returned depth first, with sibling properties in name order.
+func Check(schema json.RawMessage, d Dialect) ([]Issue, error) {
+	var root any
+	if err := json.Unmarshal(schema, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+
+	c := &checker{dialect: d}
+	if n, ok := root.(map[string]any); d.Strict && (!ok || n["type"] != "object") {
+		c.issues = append(c.issues, Issue{Keyword: "type", Message: `root schema must have type "object"`})
+	}
+	c.walk(root, "")
+	return c.issues, nil
+}
+
+// Fix rewrites a schema to remove the issues that Check reports where it can
+// without changing the shape of valid values: optional properties become
+// required but nullable, additionalProperties is set to false unless it is a
+// schema, and unsupported keywords other than applicators such as allOf are
+// dropped. It returns the indented schema and a description of each change.
+func Fix(schema json.RawMessage, d Dialect) (json.RawMessage, []string, error) {
+	var root any
+	if err := json.Unmarshal(schema, &root); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+
+	// Keys keep their original order, as providers generate properties in
+	// schema order
+	order, _ := index(schema)
+	c := &checker{dialect: d, fix: true, order: order}
+	c.walk(root, "")
+
+	var buf bytes.Buffer
+	if err := marshalOrdered(&buf, root, "", order); err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal schema: %w", err)
+	}
+	var fixed bytes.Buffer
+	if err := json.Indent(&fixed, buf.Bytes(), "", "  "); err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal schema: %w", err)
+	}
+	return fixed.Bytes(), c.changes, nil
+}
+
+// Lines returns the line on which each value in a JSON document starts, by
+// JSON pointer. Values after a syntax error are left out.
+func Lines(data []byte) map[string]int {
+	_, lines := index(data)
+	return lines
+}
+
+// index returns the keys of each object in a JSON document in the order they
+// appear, and the line on which each value starts, both by JSON pointer.
+// Values after a syntax error are left out.
+func index(data []byte) (order map[string][]string, lines map[string]int) {
+	order = make(map[string][]string)
+	lines = make(map[string]int)
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	var walk func(pointer string) bool
+	walk = func(pointer string) bool {
+		lines[pointer] = util.LineAt(data, util.ValueStart(data, int(dec.InputOffset())))
+		tok, err := dec.Token()
+		if err != nil {
+			return false
+		}
+		switch tok {
+		case json.Delim('{'):
+			for dec.More() {
+				key, err := dec.Token()
+				if err != nil {
+					return false
+				}
+				name, _ := key.(string)
+				order[pointer] = append(order[pointer], name)
+				if !walk(pointer + "/" + EscapePointer(name)) {
+					return false
+				}
+			}
+		case json.Delim('['):
+			for i := 0; dec.More(); i++ {
+				if !walk(pointer + "/" + strconv.Itoa(i)) {
+					return false
+				}
+			}
+		default:
+			return true
+		}
+		_, err = dec.Token()
+		return err == nil
+	}
+	walk("")
+	return order, lines
+}
+
+// marshalOrdered writes a JSON value with the keys of each object in the
+// order given for its pointer, followed by any other keys in name order.
+func marshalOrdered(buf *bytes.Buffer, value any, pointer string, order map[string][]string) error {
+	switch v := value.(type) {
+	case map[string]any:
+		keys := slices.DeleteFunc(slices.Clone(order[pointer]), func(k string) bool {
+			_, ok := v[k]
+			return !ok
+		})
+		for _, k := range slices.Sorted(maps.Keys(v)) {
+			if !slices.Contains(keys, k) {
+				keys = append(keys, k)
+			}
+		}
+
+		buf.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			name, _ := json.Marshal(k)
+			buf.Write(name)
+			buf.WriteByte(':')
+			if err := marshalOrdered(buf, v[k], pointer+"/"+EscapePointer(k), order); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case []any:
+		buf.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := marshalOrdered(buf, item, pointer+"/"+strconv.Itoa(i), order); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		buf.Write(data)
+	}
+	return nil
+}
+
+// checker walks a schema, recording issues or fixing them.
+type checker struct {
+	// dialect is the dialect being checked against.
+	dialect Dialect
+	// fix rewrites nodes instead of recording issues.
+	fix bool
+	// issues are the issues found so far.
+	issues []Issue
+	// changes describe the fixes made so far.
+	changes []string
+	// order are the keys of each object in the original schema, by JSON
+	// pointer. Fixes that move a node update it.
+	order map[string][]string
+}
+
+// report records an issue or, when fixing, the change that fixed it.
+// Issues that cannot be fixed are not reported when fixing.
+func (c *checker) report(pointer, keyword string, fixable bool, format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	if !c.fix {
+		c.issues = append(c.issues, Issue{Pointer: pointer, Keyword: keyword, Message: msg})
+	} else if fixable {
+		c.changes = append(c.changes, fmt.Sprintf("%s: %s", util.PathOrRoot(pointer), msg))
+	}
+}
+
+// verb returns the phrase describing an issue, or its fix when fixing.
+func (c *checker) verb(issue, fixed string) string {
+	if c.fix {
+		return fixed
+	}
+	return issue
+}
+
+// walk checks a schema node and its subschemas.
+func (c *checker) walk(node any, pointer string) {
+	n, ok := node.(map[string]any)
+	if !ok {
+		return
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(n)) {
+		if !slices.Contains(c.dialect.Unsupported, key) {
+			continue
+		}
+		if slices.Contains(applicators, key) {
+			c.report(pointer, key, false, "unsupported keyword %q", key)
+			continue
+		}
+		if c.fix {
+			delete(n, key)
+		}
+		c.report(pointer, key, true, "%s %q", c.verb("unsupported keyword", "dropped keyword"), key)
+	}
+
+	props, _ := n["properties"].(map[string]any)
+	if c.dialect.Strict && (declares(n, "object") || props != nil) {
+		c.closeObject(n, pointer)
+		c.requireAll(n, props, pointer)
+	}
+
+	for _, key := range []string{"properties", "$defs", "definitions"} {
+		children, _ := n[key].(map[string]any)
+		for _, name := range slices.Sorted(maps.Keys(children)) {
+			c.walk(children[name], pointer+"/"+key+"/"+EscapePointer(name))
+		}
+	}
+	for _, key := range []string{"items", "additionalProperties", "not", "if", "then", "else"} {
+		c.walk(n[key], pointer+"/"+key)
+	}
+	for _, key := range []string{"prefixItems", "anyOf", "oneOf", "allOf"} {
+		branches, _ := n[key].([]any)
+		for i, branch := range branches {
+			c.walk(branch, pointer+"/"+key+"/"+strconv.Itoa(i))
+		}
+	}
+}
+
+// closeObject requires an object to set additionalProperties to false. A
+// schema for additional properties, as for a map, cannot be fixed without
+// rejecting the maps it allows, so it is left in place.
+func (c *checker) closeObject(n map[string]any, pointer string) {
+	switch n["additionalProperties"].(type) {
+	case bool:
+		if n["additionalProperties"] == false {
+			return
+		}
+	case map[string]any:
+		c.report(pointer, "additionalProperties", false, "additionalProperties must be false, not a schema")
+		return
+	}
+	if c.fix {
+		n["additionalProperties"] = false
+	}
+	c.report(pointer, "additionalProperties", true, "additionalProperties %s", c.verb("must be false", "set to false"))
+}
+
+// requireAll requires an object to list all of its properties as required.
+// When fixing, optional properties are made nullable so that a model can
+// still leave them out by returning null.
+func (c *checker) requireAll(n, props map[string]any, pointer string) {
+	required, _ := n["required"].([]any)
+	for _, name := range slices.Sorted(maps.Keys(props)) {
+		if slices.Contains(required, any(name)) {
+			continue
+		}
+		if c.fix {
+			var wrapped bool
+			props[name], wrapped = nullable(props[name])
+			if wrapped {
+				c.move(pointer+"/properties/"+EscapePointer(name), pointer+"/properties/"+EscapePointer(name)+"/anyOf/0")
+			}
+			required = append(required, name)
+			n["required"] = required
+		}
+		c.report(pointer, "required", true, "property %q %s", name, c.verb("must be required", "made required and nullable"))
+	}
+}
+
+// move records that the node at pointer from now sits at pointer to, so that
+// its keys and those of its subschemas keep their order.
+func (c *checker) move(from, to string) {
+	moved := make(map[string][]string)
+	for pointer, keys := range c.order {
+		if pointer == from || strings.HasPrefix(pointer, from+"/") {
+			moved[to+strings.TrimPrefix(pointer, from)] = keys
+		}
+	}
+	maps.Copy(c.order, moved)
+}
+
+// declares reports whether a node's type is t or a list including t.
+func declares(n map[string]any, t string) bool {
+	switch v := n["type"].(type) {
+	case string:
+		return v == t
+	case []any:
+		return slices.Contains(v, any(t))
+	}
+	return false
+}
+
+// nullable returns a schema that also allows null. Null is added to the
+// node's type and enum where it has either, and otherwise the node is
+// wrapped in an anyOf, in which case wrapped is true.
+func nullable(node any) (result any, wrapped bool) {
+	n, ok := node.(map[string]any)
+	if !ok {
+		return node, false
+	}
+
+	_, hasRef := n["$ref"]
+	_, hasConst := n["const"]
+	if !hasRef && !hasConst {
+		var types []any
+		switch t := n["type"].(type) {
+		case string:
+			types = []any{t}
+		case []any:
+			types = t
+		}
+		if len(types) > 0 {
+			if !slices.Contains(types, any("null")) {
+				n["type"] = append(types, "null")
+			}
+			if values, ok := n["enum"].([]any); ok && !slices.Contains(values, nil) {
+				n["enum"] = append(values, nil)
+			}
+			return n, false
+		}
+	}
+
+	if values, ok := n["enum"].([]any); ok && !hasRef && !hasConst && n["type"] == nil {
+		if !slices.Contains(values, nil) {
+			n["enum"] = append(values, nil)
+		}
+		return n, false
+	}
+	if branches, ok := n["anyOf"].([]any); ok && len(n) == 1 {
+		n["anyOf"] = append(branches, map[string]any{"type": "null"})
+		return n, false
+	}
+	return map[string]any{"anyOf": []any{n, map[string]any{"type": "null"}}}, true
+}
+
+// EscapePointer escapes an object key for use in a JSON pointer.
+func EscapePointer(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
@@ -0,0 +1,168 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		// want are the pointer and keyword of each issue, in order.
+		want [][2]string
+	}{
+		{
+			name:   "strict",
+			schema: `{"type":"object","properties":{"a":{"type":"string"}},"required":["a"],"additionalProperties":false}`,
+		},
+		{
+			name:   "root not an object",
+			schema: `{"type":"array","items":{"type":"string"}}`,
+			want:   [][2]string{{"", "type"}},
+		},
+		{
+			name:   "optional property and open object",
+			schema: `{"type":"object","properties":{"b":{"type":"string"},"a":{"type":"string"}},"required":["b"]}`,
+			want:   [][2]string{{"", "additionalProperties"}, {"", "required"}},
+		},
+		{
+			name:   "schema-valued additionalProperties",
+			schema: `{"type":"object","properties":{"tags":{"type":"object","additionalProperties":{"type":"string"}}},"required":["tags"],"additionalProperties":false}`,
+			want:   [][2]string{{"/properties/tags", "additionalProperties"}},
+		},
+		{
+			name:   "unsupported keywords",
+			schema: `{"type":"object","properties":{"a":{"type":"string","minLength":1,"allOf":[{"pattern":"x"}]}},"required":["a"],"additionalProperties":false}`,
+			want:   [][2]string{{"/properties/a", "allOf"}, {"/properties/a", "minLength"}, {"/properties/a/allOf/0", "pattern"}},
+		},
+		{
+			name:   "nested in definitions and items",
+			schema: `{"type":"object","properties":{"list":{"type":"array","items":{"$ref":"#/$defs/item"}}},"required":["list"],"additionalProperties":false,"$defs":{"item":{"type":"object","properties":{"id":{"type":"integer"}}}}}`,
+			want:   [][2]string{{"/$defs/item", "additionalProperties"}, {"/$defs/item", "required"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues, err := Check(json.RawMessage(tt.schema), OpenAI)
+			if err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+			var got [][2]string
+			for _, issue := range issues {
+				got = append(got, [2]string{issue.Pointer, issue.Keyword})
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Check() = %v, want %v", issues, tt.want)
+			}
+		})
+	}
+}
+
+func TestFix(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		want    string
+		changes int
+	}{
+		{
+			name:   "already strict",
+			schema: `{"type":"object","properties":{"a":{"type":"string"}},"required":["a"],"additionalProperties":false}`,
+			want:   `{"type":"object","properties":{"a":{"type":"string"}},"required":["a"],"additionalProperties":false}`,
+		},
+		{
+			name:    "nullable type",
+			schema:  `{"type":"object","properties":{"a":{"type":"string"}}}`,
+			want:    `{"type":"object","properties":{"a":{"type":["string","null"]}},"additionalProperties":false,"required":["a"]}`,
+			changes: 2,
+		},
+		{
+			name:    "type list that allows null",
+			schema:  `{"type":"object","properties":{"a":{"type":["integer","null"]}},"additionalProperties":false}`,
+			want:    `{"type":"object","properties":{"a":{"type":["integer","null"]}},"additionalProperties":false,"required":["a"]}`,
+			changes: 1,
+		},
+		{
+			name:    "typed enum",
+			schema:  `{"type":"object","properties":{"a":{"type":"string","enum":["x","y"]}},"additionalProperties":false}`,
+			want:    `{"type":"object","properties":{"a":{"type":["string","null"],"enum":["x","y",null]}},"additionalProperties":false,"required":["a"]}`,
+			changes: 1,
+		},
+		{
+			name:    "untyped enum",
+			schema:  `{"type":"object","properties":{"a":{"enum":[1,2]}},"additionalProperties":false}`,
+			want:    `{"type":"object","properties":{"a":{"enum":[1,2,null]}},"additionalProperties":false,"required":["a"]}`,
+			changes: 1,
+		},
+		{
+			name:    "anyOf",
+			schema:  `{"type":"object","properties":{"a":{"anyOf":[{"type":"string"},{"type":"integer"}]}},"additionalProperties":false}`,
+			want:    `{"type":"object","properties":{"a":{"anyOf":[{"type":"string"},{"type":"integer"},{"type":"null"}]}},"additionalProperties":false,"required":["a"]}`,
+			changes: 1,
+		},
+		{
+			name:    "reference",
+			schema:  `{"type":"object","properties":{"a":{"$ref":"#/$defs/a"}},"additionalProperties":false,"$defs":{"a":{"type":"string"}}}`,
+			want:    `{"type":"object","properties":{"a":{"anyOf":[{"$ref":"#/$defs/a"},{"type":"null"}]}},"additionalProperties":false,"$defs":{"a":{"type":"string"}},"required":["a"]}`,
+			changes: 1,
+		},
+		{
+			name:    "const",
+			schema:  `{"type":"object","properties":{"a":{"type":"string","const":"x"}},"additionalProperties":false}`,
+			want:    `{"type":"object","properties":{"a":{"anyOf":[{"type":"string","const":"x"},{"type":"null"}]}},"additionalProperties":false,"required":["a"]}`,
+			changes: 1,
+		},
+		{
+			name:    "nested object",
+			schema:  `{"type":"object","properties":{"o":{"type":"object","properties":{"b":{"type":"string"}},"required":["b"]}},"required":["o"]}`,
+			want:    `{"type":"object","properties":{"o":{"type":"object","properties":{"b":{"type":"string"}},"required":["b"],"additionalProperties":false}},"required":["o"],"additionalProperties":false}`,
+			changes: 2,
+		},
+		{
+			name:   "schema-valued additionalProperties",
+			schema: `{"type":"object","properties":{"tags":{"type":"object","additionalProperties":{"type":"string"}}},"required":["tags"],"additionalProperties":false}`,
+			want:   `{"type":"object","properties":{"tags":{"type":"object","additionalProperties":{"type":"string"}}},"required":["tags"],"additionalProperties":false}`,
+		},
+		{
+			name:    "unsupported keywords",
+			schema:  `{"type":"object","properties":{"a":{"type":"string","minLength":1,"format":"email","allOf":[{"maxLength":5}]}},"required":["a"],"additionalProperties":false}`,
+			want:    `{"type":"object","properties":{"a":{"type":"string","allOf":[{}]}},"required":["a"],"additionalProperties":false}`,
+			changes: 3,
+		},
+		{
+			name:    "key order",
+			schema:  `{"required":["z"],"properties":{"z":{"type":"string"},"m":{"type":"number"},"a":{"type":"boolean"}},"type":"object"}`,
+			want:    `{"required":["z","a","m"],"properties":{"z":{"type":"string"},"m":{"type":["number","null"]},"a":{"type":["boolean","null"]}},"type":"object","additionalProperties":false}`,
+			changes: 3,
+		},
+		{
+			name:    "key order of a wrapped node",
+			schema:  `{"type":"object","properties":{"o":{"properties":{"z":{"type":"string"},"a":{"type":"string"}},"required":["z","a"]}},"additionalProperties":false}`,
+			want:    `{"type":"object","properties":{"o":{"anyOf":[{"properties":{"z":{"type":"string"},"a":{"type":"string"}},"required":["z","a"],"additionalProperties":false},{"type":"null"}]}},"additionalProperties":false,"required":["o"]}`,
+			changes: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixed, changes, err := Fix(json.RawMessage(tt.schema), OpenAI)
+			if err != nil {
+				t.Fatalf("Fix() error = %v", err)
+			}
+			var got bytes.Buffer
+			if err := json.Compact(&got, fixed); err != nil {
+				t.Fatalf("Fix() returned invalid JSON: %v", err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("Fix() =\n%s\nwant\n%s", got.String(), tt.want)
+			}
+			if len(changes) != tt.changes {
+				t.Errorf("changes = %q, want %d", changes, tt.changes)
+			}
+		})
+	}
+}
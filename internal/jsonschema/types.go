@@ -0,0 +1,58 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"go.carr.sh/litmus/internal/util"
+)
+
+// Types returns the declared types of every field in a schema, keyed by path
+// as for Enums. Where a field has several declarations, such as in the
+// branches of an anyOf, their types are combined in order of appearance.
+// The types of enum and const values count as declared. Fields without a
+// declared type are left out.
+func Types(schema json.RawMessage) (map[string][]string, error) {
+	var root map[string]any
+	if err := json.Unmarshal(schema, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+
+	types := make(map[string][]string)
+	walkFields(root, root, "", func(node map[string]any, path string) {
+		key := util.PathOrRoot(path)
+		for _, t := range declaredTypes(node) {
+			if !slices.Contains(types[key], t) {
+				types[key] = append(types[key], t)
+			}
+		}
+	}, 0)
+	return types, nil
+}
+
+// declaredTypes returns the types a schema node declares, including the
+// types of its enum and const values.
+func declaredTypes(node map[string]any) []string {
+	var declared []string
+	switch t := node["type"].(type) {
+	case string:
+		declared = []string{t}
+	case []any:
+		for _, v := range t {
+			if s, ok := v.(string); ok {
+				declared = append(declared, s)
+			}
+		}
+	}
+
+	// Enums and constants imply the types of their values
+	values, _ := node["enum"].([]any)
+	if c, ok := node["const"]; ok {
+		values = append(values, c)
+	}
+	for _, v := range values {
+		declared = append(declared, typeOf(v))
+	}
+	return declared
+}
@@ -0,0 +1,44 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestTypes(t *testing.T) {
+	schema := `{
+  "$defs": {"id": {"type": ["string", "integer"]}},
+  "type": "object",
+  "properties": {
+    "id": {"$ref": "#/$defs/id"},
+    "score": {"anyOf": [{"type": "number"}, {"type": "null"}, {"type": "number"}]},
+    "kind": {"enum": ["a", 1]},
+    "version": {"const": true},
+    "tags": {"type": "array", "items": {"type": "string"}},
+    "untyped": {"description": "anything"}
+  }
+}`
+
+	got, err := Types(json.RawMessage(schema))
+	if err != nil {
+		t.Fatalf("Types() error = %v", err)
+	}
+	want := map[string][]string{
+		"(root)":  {"object"},
+		"id":      {"string", "integer"},
+		"score":   {"number", "null"},
+		"kind":    {"string", "number"},
+		"version": {"boolean"},
+		"tags":    {"array"},
+		"tags[*]": {"string"},
+	}
+	if len(got) != len(want) {
+		t.Errorf("Types() = %v, want %d fields", got, len(want))
+	}
+	for path, types := range want {
+		if !slices.Equal(got[path], types) {
+			t.Errorf("Types()[%q] = %v, want %v", path, got[path], types)
+		}
+	}
+}
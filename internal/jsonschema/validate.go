@@ -0,0 +1,412 @@
+package jsonschema
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"regexp"
+	"slices"
+	"unicode/utf8"
+
+	"go.carr.sh/litmus/internal/types"
+	"go.carr.sh/litmus/internal/util"
+)
+
+// maxValidationDepth limits how many $ref references are followed while
+// validating a value, so that reference cycles terminate.
+const maxValidationDepth = 100
+
+// dataKeywords hold JSON values rather than subschemas, so they are not
+// searched for patterns.
+var dataKeywords = []string{"enum", "const", "default", "examples"}
+
+// Schema is a compiled JSON schema that validates values locally. It
+// supports the draft 2020-12 keywords used for structured output: $ref to
+// local definitions, type, enum, const, the numeric, string, array and
+// object constraints, and the anyOf, oneOf, allOf, not and if/then/else
+// applicators. Other keywords, such as format, are ignored.
+type Schema struct {
+	// root is the root schema, an object or a boolean.
+	root any
+	// patterns are the compiled pattern and patternProperties expressions.
+	patterns map[string]*regexp.Regexp
+}
+
+// Compile parses a JSON schema and compiles its regular expressions. Patterns
+// use Go's RE2 syntax, so ECMA-262 features such as lookahead are rejected.
+func Compile(schema json.RawMessage) (*Schema, error) {
+	var root any
+	if err := json.Unmarshal(schema, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+
+	s := &Schema{root: root, patterns: make(map[string]*regexp.Regexp)}
+	if err := s.compilePatterns(root); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// compilePatterns compiles every pattern in a schema node and its subschemas.
+func (s *Schema) compilePatterns(node any) error {
+	switch n := node.(type) {
+	case map[string]any:
+		if p, ok := n["pattern"].(string); ok {
+			if err := s.compile(p); err != nil {
+				return err
+			}
+		}
+		if props, ok := n["patternProperties"].(map[string]any); ok {
+			for p := range props {
+				if err := s.compile(p); err != nil {
+					return err
+				}
+			}
+		}
+		for key, child := range n {
+			if slices.Contains(dataKeywords, key) {
+				continue
+			}
+			if err := s.compilePatterns(child); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, child := range n {
+			if err := s.compilePatterns(child); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// compile compiles a pattern if it has not been compiled yet.
+func (s *Schema) compile(pattern string) error {
+	if _, ok := s.patterns[pattern]; ok {
+		return nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+	s.patterns[pattern] = re
+	return nil
+}
+
+// Validate checks a JSON value against the schema, returning the violations
+// found, sorted by path.
+func (s *Schema) Validate(data json.RawMessage) ([]types.SchemaViolation, error) {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, fmt.Errorf("failed to parse response JSON: %w", err)
+	}
+
+	v := &validator{schema: s}
+	v.validate("", s.root, value, 0)
+	slices.SortStableFunc(v.violations, func(a, b types.SchemaViolation) int {
+		return cmp.Compare(a.Path, b.Path)
+	})
+	return v.violations, nil
+}
+
+// validator collects the violations of a single value.
+type validator struct {
+	// schema is the schema being validated against.
+	schema *Schema
+	// violations are the violations found so far.
+	violations []types.SchemaViolation
+}
+
+// valid reports whether a value matches a subschema, without recording any
+// violations.
+func (v *validator) valid(path string, node, value any, depth int) bool {
+	sub := &validator{schema: v.schema}
+	sub.validate(path, node, value, depth)
+	return len(sub.violations) == 0
+}
+
+// fail records a violation.
+func (v *validator) fail(path, keyword, format string, args ...any) {
+	v.violations = append(v.violations, types.SchemaViolation{
+		Path:    util.PathOrRoot(path),
+		Keyword: keyword,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// validate checks a value against a schema node.
+func (v *validator) validate(path string, node, value any, depth int) {
+	if b, ok := node.(bool); ok {
+		if !b {
+			v.fail(path, "false", "no value is allowed")
+		}
+		return
+	}
+	n, ok := node.(map[string]any)
+	if !ok {
+		return
+	}
+
+	if ref, ok := n["$ref"].(string); ok {
+		target := ResolveRef(asObject(v.schema.root), ref)
+		if target == nil || depth >= maxValidationDepth {
+			v.fail(path, "$ref", "cannot resolve %q", ref)
+		} else {
+			v.validate(path, target, value, depth+1)
+		}
+	}
+
+	v.validateGeneric(path, n, value)
+	v.validateApplicators(path, n, value, depth)
+
+	switch val := value.(type) {
+	case float64:
+		v.validateNumber(path, n, val)
+	case string:
+		v.validateString(path, n, val)
+	case []any:
+		v.validateArray(path, n, val, depth)
+	case map[string]any:
+		v.validateObject(path, n, val, depth)
+	}
+}
+
+// validateGeneric checks the type, enum and const keywords.
+func (v *validator) validateGeneric(path string, n map[string]any, value any) {
+	if t, ok := n["type"]; ok {
+		var allowed []string
+		switch t := t.(type) {
+		case string:
+			allowed = []string{t}
+		case []any:
+			for _, a := range t {
+				if s, ok := a.(string); ok {
+					allowed = append(allowed, s)
+				}
+			}
+		}
+		if !slices.ContainsFunc(allowed, func(a string) bool { return hasType(value, a) }) {
+			v.fail(path, "type", "expected %s, got %s", describeTypes(allowed), typeOf(value))
+		}
+	}
+
+	if values, ok := n["enum"].([]any); ok {
+		if !slices.ContainsFunc(values, func(e any) bool { return equal(e, value) }) {
+			v.fail(path, "enum", "%s is not one of %s", describe(value), describe(values))
+		}
+	}
+
+	if c, ok := n["const"]; ok && !equal(c, value) {
+		v.fail(path, "const", "%s is not %s", describe(value), describe(c))
+	}
+}
+
+// validateApplicators checks the allOf, anyOf, oneOf, not and if/then/else
+// keywords.
+func (v *validator) validateApplicators(path string, n map[string]any, value any, depth int) {
+	if all, ok := n["allOf"].([]any); ok {
+		for _, sub := range all {
+			v.validate(path, sub, value, depth)
+		}
+	}
+
+	if anyOf, ok := n["anyOf"].([]any); ok {
+		if !slices.ContainsFunc(anyOf, func(sub any) bool { return v.valid(path, sub, value, depth) }) {
+			v.fail(path, "anyOf", "%s does not match any of the allowed schemas", describe(value))
+		}
+	}
+
+	if oneOf, ok := n["oneOf"].([]any); ok {
+		matched := 0
+		for _, sub := range oneOf {
+			if v.valid(path, sub, value, depth) {
+				matched++
+			}
+		}
+		if matched != 1 {
+			v.fail(path, "oneOf", "%s matches %d of the schemas instead of exactly one", describe(value), matched)
+		}
+	}
+
+	if not, ok := n["not"]; ok && v.valid(path, not, value, depth) {
+		v.fail(path, "not", "%s matches a disallowed schema", describe(value))
+	}
+
+	if cond, ok := n["if"]; ok {
+		if v.valid(path, cond, value, depth) {
+			if then, ok := n["then"]; ok {
+				v.validate(path, then, value, depth)
+			}
+		} else if els, ok := n["else"]; ok {
+			v.validate(path, els, value, depth)
+		}
+	}
+}
+
+// validateNumber checks the numeric constraints.
+func (v *validator) validateNumber(path string, n map[string]any, val float64) {
+	if limit, ok := n["minimum"].(float64); ok && val < limit {
+		v.fail(path, "minimum", "%g is less than %g", val, limit)
+	}
+	if limit, ok := n["maximum"].(float64); ok && val > limit {
+		v.fail(path, "maximum", "%g is greater than %g", val, limit)
+	}
+	if limit, ok := n["exclusiveMinimum"].(float64); ok && val <= limit {
+		v.fail(path, "exclusiveMinimum", "%g is not greater than %g", val, limit)
+	}
+	if limit, ok := n["exclusiveMaximum"].(float64); ok && val >= limit {
+		v.fail(path, "exclusiveMaximum", "%g is not less than %g", val, limit)
+	}
+	if m, ok := n["multipleOf"].(float64); ok && m > 0 {
+		if q := val / m; math.Abs(q-math.Round(q)) > 1e-9 {
+			v.fail(path, "multipleOf", "%g is not a multiple of %g", val, m)
+		}
+	}
+}
+
+// validateString checks the string constraints.
+func (v *validator) validateString(path string, n map[string]any, val string) {
+	length := utf8.RuneCountInString(val)
+	if limit, ok := n["minLength"].(float64); ok && float64(length) < limit {
+		v.fail(path, "minLength", "length %d is less than %g", length, limit)
+	}
+	if limit, ok := n["maxLength"].(float64); ok && float64(length) > limit {
+		v.fail(path, "maxLength", "length %d is greater than %g", length, limit)
+	}
+	if p, ok := n["pattern"].(string); ok {
+		if re := v.schema.patterns[p]; re != nil && !re.MatchString(val) {
+			v.fail(path, "pattern", "%s does not match /%s/", describe(val), p)
+		}
+	}
+}
+
+// validateArray checks the array constraints and the elements.
+func (v *validator) validateArray(path string, n map[string]any, val []any, depth int) {
+	if limit, ok := n["minItems"].(float64); ok && float64(len(val)) < limit {
+		v.fail(path, "minItems", "%d items is fewer than %g", len(val), limit)
+	}
+	if limit, ok := n["maxItems"].(float64); ok && float64(len(val)) > limit {
+		v.fail(path, "maxItems", "%d items is more than %g", len(val), limit)
+	}
+	if unique, ok := n["uniqueItems"].(bool); ok && unique {
+		for i := range val {
+			if slices.ContainsFunc(val[:i], func(e any) bool { return equal(e, val[i]) }) {
+				v.fail(path, "uniqueItems", "item %d is a duplicate", i)
+				break
+			}
+		}
+	}
+
+	prefix, _ := n["prefixItems"].([]any)
+	for i, item := range val {
+		itemPath := fmt.Sprintf("%s[%d]", path, i)
+		if i < len(prefix) {
+			v.validate(itemPath, prefix[i], item, depth)
+		} else if items, ok := n["items"]; ok {
+			v.validate(itemPath, items, item, depth)
+		}
+	}
+}
+
+// validateObject checks the object constraints and the properties.
+func (v *validator) validateObject(path string, n map[string]any, val map[string]any, depth int) {
+	if limit, ok := n["minProperties"].(float64); ok && float64(len(val)) < limit {
+		v.fail(path, "minProperties", "%d properties is fewer than %g", len(val), limit)
+	}
+	if limit, ok := n["maxProperties"].(float64); ok && float64(len(val)) > limit {
+		v.fail(path, "maxProperties", "%d properties is more than %g", len(val), limit)
+	}
+
+	if required, ok := n["required"].([]any); ok {
+		for _, r := range required {
+			if name, ok := r.(string); ok {
+				if _, present := val[name]; !present {
+					v.fail(util.JoinPath(path, name), "required", "required property is missing")
+				}
+			}
+		}
+	}
+
+	props, _ := n["properties"].(map[string]any)
+	patternProps, _ := n["patternProperties"].(map[string]any)
+	additional, hasAdditional := n["additionalProperties"]
+
+	for _, name := range slices.Sorted(maps.Keys(val)) {
+		propPath := util.JoinPath(path, name)
+		matched := false
+		if sub, ok := props[name]; ok {
+			v.validate(propPath, sub, val[name], depth)
+			matched = true
+		}
+		for p, sub := range patternProps {
+			if re := v.schema.patterns[p]; re != nil && re.MatchString(name) {
+				v.validate(propPath, sub, val[name], depth)
+				matched = true
+			}
+		}
+		if matched || !hasAdditional {
+			continue
+		}
+		if b, ok := additional.(bool); ok && !b {
+			v.fail(propPath, "additionalProperties", "property is not allowed")
+			continue
+		}
+		v.validate(propPath, additional, val[name], depth)
+	}
+}
+
+// hasType reports whether a value has a JSON Schema type. Whole numbers are
+// integers.
+func hasType(value any, t string) bool {
+	switch t {
+	case "integer":
+		f, ok := value.(float64)
+		return ok && f == math.Trunc(f)
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	}
+	return typeOf(value) == t
+}
+
+// typeOf returns the JSON type of a value.
+func typeOf(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return "unknown"
+}
+
+// describeTypes formats a list of allowed types.
+func describeTypes(types []string) string {
+	if len(types) == 1 {
+		return types[0]
+	}
+	return fmt.Sprintf("one of %v", types)
+}
+
+// describe formats a value as JSON for use in a message, truncated to 60
+// characters.
+func describe(v any) string {
+	return util.Truncate(util.Describe(v), 60)
+}
+
+// asObject returns a schema node as an object, or nil for a boolean schema.
+func asObject(node any) map[string]any {
+	n, _ := node.(map[string]any)
+	return n
+}
@@ -0,0 +1,148 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		data   string
+		// want are the path and keyword of each violation, in order.
+		want [][2]string
+	}{
+		{
+			name:   "valid",
+			schema: `{"type":"object","properties":{"name":{"type":"string"}},"required":["name"]}`,
+			data:   `{"name":"Ada"}`,
+		},
+		{
+			name:   "root type",
+			schema: `{"type":"object"}`,
+			data:   `[]`,
+			want:   [][2]string{{"(root)", "type"}},
+		},
+		{
+			name:   "whole number is an integer",
+			schema: `{"type":"integer"}`,
+			data:   `3.0`,
+		},
+		{
+			name:   "fraction is not an integer",
+			schema: `{"type":"integer"}`,
+			data:   `3.5`,
+			want:   [][2]string{{"(root)", "type"}},
+		},
+		{
+			name:   "nullable type",
+			schema: `{"type":["string","null"]}`,
+			data:   `null`,
+		},
+		{
+			name:   "required",
+			schema: `{"type":"object","required":["name","age"]}`,
+			data:   `{"name":"Ada"}`,
+			want:   [][2]string{{"age", "required"}},
+		},
+		{
+			name:   "enum",
+			schema: `{"properties":{"status":{"enum":["open","closed"]}}}`,
+			data:   `{"status":"pending"}`,
+			want:   [][2]string{{"status", "enum"}},
+		},
+		{
+			name:   "minimum and maximum",
+			schema: `{"properties":{"low":{"minimum":1},"high":{"maximum":10}}}`,
+			data:   `{"low":0,"high":11}`,
+			want:   [][2]string{{"high", "maximum"}, {"low", "minimum"}},
+		},
+		{
+			name:   "pattern",
+			schema: `{"type":"string","pattern":"^[A-Z]{3}$"}`,
+			data:   `"usd"`,
+			want:   [][2]string{{"(root)", "pattern"}},
+		},
+		{
+			name:   "nested array items",
+			schema: `{"properties":{"items":{"type":"array","items":{"properties":{"qty":{"type":"integer"}}}}}}`,
+			data:   `{"items":[{"qty":1},{"qty":"two"}]}`,
+			want:   [][2]string{{"items[1].qty", "type"}},
+		},
+		{
+			name:   "ref",
+			schema: `{"$defs":{"id":{"type":"string","minLength":2}},"properties":{"id":{"$ref":"#/$defs/id"}}}`,
+			data:   `{"id":"x"}`,
+			want:   [][2]string{{"id", "minLength"}},
+		},
+		{
+			name:   "unresolved ref",
+			schema: `{"properties":{"id":{"$ref":"#/$defs/missing"}}}`,
+			data:   `{"id":"x"}`,
+			want:   [][2]string{{"id", "$ref"}},
+		},
+		{
+			name:   "anyOf",
+			schema: `{"anyOf":[{"type":"string"},{"type":"number"}]}`,
+			data:   `true`,
+			want:   [][2]string{{"(root)", "anyOf"}},
+		},
+		{
+			name:   "oneOf matching two",
+			schema: `{"oneOf":[{"type":"number"},{"type":"integer"}]}`,
+			data:   `1`,
+			want:   [][2]string{{"(root)", "oneOf"}},
+		},
+		{
+			name:   "additionalProperties false",
+			schema: `{"properties":{"name":{}},"additionalProperties":false}`,
+			data:   `{"name":"Ada","extra":1}`,
+			want:   [][2]string{{"extra", "additionalProperties"}},
+		},
+		{
+			name:   "additionalProperties schema",
+			schema: `{"additionalProperties":{"type":"number"}}`,
+			data:   `{"a":1,"b":"x"}`,
+			want:   [][2]string{{"b", "type"}},
+		},
+		{
+			name:   "uniqueItems",
+			schema: `{"uniqueItems":true}`,
+			data:   `[1,2,1]`,
+			want:   [][2]string{{"(root)", "uniqueItems"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Compile(json.RawMessage(tt.schema))
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+			violations, err := s.Validate(json.RawMessage(tt.data))
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+
+			if len(violations) != len(tt.want) {
+				t.Fatalf("Validate() = %+v, want %v", violations, tt.want)
+			}
+			for i, v := range violations {
+				if v.Path != tt.want[i][0] || v.Keyword != tt.want[i][1] {
+					t.Errorf("violation %d = %s %s (%s), want %s %s", i, v.Path, v.Keyword, v.Message, tt.want[i][0], tt.want[i][1])
+				}
+			}
+		})
+	}
+}
+
+func TestValidateInvalidJSON(t *testing.T) {
+	s, err := Compile(json.RawMessage(`{"type":"object"}`))
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	if _, err := s.Validate(json.RawMessage(`{"name":`)); err == nil {
+		t.Error("Validate() error = nil, want an error for invalid JSON")
+	}
+}
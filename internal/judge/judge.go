@@ -0,0 +1,134 @@
+// Package judge scores free-text fields by asking a judge model how well an
+// actual value matches the expected one.
+package judge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"go.carr.sh/litmus/internal/compare"
+	"go.carr.sh/litmus/internal/provider"
+	"go.carr.sh/litmus/internal/types"
+)
+
+// DefaultRubric is the rubric used when none is configured.
+const DefaultRubric = `Score how well the actual value conveys the same meaning as the expected value.
+1 means equivalent: every fact in the expected value is present and nothing contradicts it.
+0 means unrelated or contradictory. Ignore differences in wording, order and formatting.`
+
+// instructions are appended to the rubric in the judge's system prompt.
+const instructions = `You are grading a single field of a structured output against its expected value.
+Reply with a score from 0 to 1 and a one-sentence rationale.`
+
+// schema is the structured output schema of a verdict.
+var schema = json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "score": {"type": "number", "minimum": 0, "maximum": 1},
+    "rationale": {"type": "string"}
+  },
+  "required": ["score", "rationale"],
+  "additionalProperties": false
+}`)
+
+// Judge sends fields to a judge model through a provider. Identical requests
+// are sent once per run; put a response cache in front of the provider to
+// avoid paying for them again on later runs.
+type Judge struct {
+	// provider sends the judge requests.
+	provider provider.Provider
+	// mu guards calls.
+	mu sync.Mutex
+	// calls are the requests sent so far, by request key.
+	calls map[string]*call
+}
+
+// call is a judge request that is in flight or complete.
+type call struct {
+	// once ensures the request is sent once.
+	once sync.Once
+	// verdict and err are the outcome of the request.
+	verdict *compare.Verdict
+	err     error
+}
+
+// New creates a Judge that sends requests through p.
+func New(p provider.Provider) *Judge {
+	return &Judge{provider: p, calls: make(map[string]*call)}
+}
+
+// Func returns a compare.JudgeFunc that scores fields with the given context.
+func (j *Judge) Func(ctx context.Context) compare.JudgeFunc {
+	return func(opts compare.JudgeOptions, path string, expected, actual any) (*compare.Verdict, error) {
+		return j.Score(ctx, opts, path, expected, actual)
+	}
+}
+
+// Score asks the judge model to score the actual value of the field at path
+// against the expected value. A verdict reused from an identical request
+// earlier in the run has a cost of 0.
+func (j *Judge) Score(ctx context.Context, opts compare.JudgeOptions, path string, expected, actual any) (*compare.Verdict, error) {
+	input, err := json.MarshalIndent(map[string]any{
+		"field":    path,
+		"expected": expected,
+		"actual":   actual,
+	}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal judge input: %w", err)
+	}
+
+	rubric := opts.Rubric
+	if rubric == "" {
+		rubric = DefaultRubric
+	}
+	temperature := 0.0
+	req := provider.Request{
+		Model:        opts.Model,
+		SystemPrompt: instructions + "\n\n" + rubric,
+		UserInput:    string(input),
+		Schema:       schema,
+		Params:       types.Params{Temperature: &temperature},
+	}
+
+	j.mu.Lock()
+	c, ok := j.calls[req.Key()]
+	if !ok {
+		c = &call{}
+		j.calls[req.Key()] = c
+	}
+	j.mu.Unlock()
+
+	sent := false
+	c.once.Do(func() {
+		sent = true
+		c.verdict, c.err = j.complete(ctx, req)
+	})
+	if c.err != nil || sent {
+		return c.verdict, c.err
+	}
+
+	// The request was paid for by the field that sent it
+	verdict := *c.verdict
+	verdict.Cost = new(float64)
+	return &verdict, nil
+}
+
+// complete sends a judge request and parses the verdict.
+func (j *Judge) complete(ctx context.Context, req provider.Request) (*compare.Verdict, error) {
+	result, err := j.provider.Complete(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var verdict compare.Verdict
+	if err := json.Unmarshal(result.Response, &verdict); err != nil {
+		return nil, fmt.Errorf("failed to parse judge verdict: %w", err)
+	}
+	if verdict.Score < 0 || verdict.Score > 1 {
+		return nil, fmt.Errorf("judge score %g is not between 0 and 1", verdict.Score)
+	}
+	verdict.Cost = result.Cost
+	return &verdict, nil
+}
@@ -0,0 +1,95 @@
+package judge
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+	"testing"
+
+	"go.carr.sh/litmus/internal/compare"
+	"go.carr.sh/litmus/internal/provider"
+)
+
+// stubProvider answers every request with the same verdict.
+type stubProvider struct {
+	response string
+	cost     *float64
+	calls    atomic.Int32
+}
+
+func (s *stubProvider) Complete(ctx context.Context, req provider.Request) (*provider.CompletionResult, error) {
+	s.calls.Add(1)
+	return &provider.CompletionResult{Response: json.RawMessage(s.response), Cost: s.cost}, nil
+}
+
+func TestScore(t *testing.T) {
+	cost := 0.003
+	p := &stubProvider{response: `{"score":0.8,"rationale":"close"}`, cost: &cost}
+	j := New(p)
+	opts := compare.JudgeOptions{Model: "judge"}
+
+	first, err := j.Score(context.Background(), opts, "summary", "a cat", "one cat")
+	if err != nil {
+		t.Fatalf("Score() error = %v", err)
+	}
+	if first.Score != 0.8 || first.Rationale != "close" {
+		t.Errorf("Score() = %+v, want score 0.8 and rationale", first)
+	}
+	if first.Cost == nil || *first.Cost != cost {
+		t.Errorf("Cost = %v, want %v", first.Cost, cost)
+	}
+
+	// An identical request reuses the verdict without paying for it again
+	second, err := j.Score(context.Background(), opts, "summary", "a cat", "one cat")
+	if err != nil {
+		t.Fatalf("Score() error = %v", err)
+	}
+	if p.calls.Load() != 1 {
+		t.Errorf("provider called %d times, want 1", p.calls.Load())
+	}
+	if second.Score != 0.8 || second.Cost == nil || *second.Cost != 0 {
+		t.Errorf("reused verdict = %+v, want score 0.8 and cost 0", second)
+	}
+	if *first.Cost != cost {
+		t.Errorf("first cost changed to %v", *first.Cost)
+	}
+
+	// A different field is a different request
+	if _, err := j.Score(context.Background(), opts, "title", "a cat", "one cat"); err != nil {
+		t.Fatalf("Score() error = %v", err)
+	}
+	if p.calls.Load() != 2 {
+		t.Errorf("provider called %d times, want 2", p.calls.Load())
+	}
+}
+
+func TestScoreUnpriced(t *testing.T) {
+	j := New(&stubProvider{response: `{"score":1,"rationale":"same"}`})
+	verdict, err := j.Score(context.Background(), compare.JudgeOptions{Model: "judge"}, "summary", "a", "b")
+	if err != nil {
+		t.Fatalf("Score() error = %v", err)
+	}
+	if verdict.Cost != nil {
+		t.Errorf("Cost = %v, want nil", *verdict.Cost)
+	}
+}
+
+func TestScoreInvalidVerdict(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+	}{
+		{"not JSON", `score: 1`},
+		{"above one", `{"score":1.5,"rationale":"great"}`},
+		{"negative", `{"score":-1,"rationale":"bad"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			j := New(&stubProvider{response: tt.response})
+			if _, err := j.Score(context.Background(), compare.JudgeOptions{Model: "judge"}, "summary", "a", "b"); err == nil {
+				t.Error("Score() error = nil, want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+// Package ollama provides an HTTP client for the native Ollama chat API.
+package ollama
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.carr.sh/litmus/internal/provider"
+	"go.carr.sh/litmus/internal/types"
+)
+
+const (
+	defaultBaseURL    = "http://localhost:11434"
+	defaultMaxRetries = 3
+	defaultRetryDelay = time.Second
+	// Local models may need to be loaded into memory before the first
+	// response, so the timeout is more generous than for hosted APIs.
+	defaultTimeout = 300 * time.Second
+)
+
+// Client is an HTTP client for the Ollama API.
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+	headers    map[string]string
+	maxRetries int
+	retryDelay time.Duration
+}
+
+var _ provider.Provider = (*Client)(nil)
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithBaseURL sets a custom base URL for the API. A bare host such as
+// "127.0.0.1:11434" (the OLLAMA_HOST format) is accepted.
+func WithBaseURL(url string) Option {
+	return func(c *Client) {
+		if !strings.Contains(url, "://") {
+			url = "http://" + url
+		}
+		c.baseURL = strings.TrimSuffix(url, "/")
+	}
+}
+
+// WithHTTPClient sets a custom HTTP client.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithRetry configures retry behavior.
+func WithRetry(maxRetries int, retryDelay time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.retryDelay = retryDelay
+	}
+}
+
+// WithTimeout sets the HTTP client timeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.httpClient.Timeout = timeout
+	}
+}
+
+// WithHeaders sets extra HTTP headers sent with every request.
+func WithHeaders(headers map[string]string) Option {
+	return func(c *Client) {
+		for k, v := range headers {
+			c.headers[k] = v
+		}
+	}
+}
+
+// NewClient creates a new Ollama API client. Ollama does not require an API key.
+func NewClient(opts ...Option) *Client {
+	c := &Client{
+		httpClient: &http.Client{Timeout: defaultTimeout},
+		baseURL:    defaultBaseURL,
+		headers:    make(map[string]string),
+		maxRetries: defaultMaxRetries,
+		retryDelay: defaultRetryDelay,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Message represents a chat message.
+type Message struct {
+	// Role is the role of the message, either "system" or "user".
+	Role string `json:"role"`
+	// Content is the plain text content of the message.
+	Content string `json:"content"`
+}
+
+// ChatRequest represents an /api/chat request.
+type ChatRequest struct {
+	// Model is the name of the model to use.
+	Model string `json:"model"`
+	// Messages is the list of messages to send to the model.
+	Messages []Message `json:"messages"`
+	// Stream must be false to receive a single response object.
+	Stream bool `json:"stream"`
+	// Format is the JSON schema the response must conform to.
+	Format json.RawMessage `json:"format,omitempty"`
+	// Options are model parameters such as temperature.
+	Options *Options `json:"options,omitempty"`
+}
+
+// Options are Ollama model parameters.
+type Options struct {
+	// Temperature controls randomness.
+	Temperature *float64 `json:"temperature,omitempty"`
+	// TopP is the nucleus sampling probability mass.
+	TopP *float64 `json:"top_p,omitempty"`
+	// Seed requests deterministic sampling.
+	Seed *int `json:"seed,omitempty"`
+	// NumPredict is the maximum number of tokens to generate.
+	NumPredict *int `json:"num_predict,omitempty"`
+	// Stop is a list of sequences that end generation.
+	Stop []string `json:"stop,omitempty"`
+	// FrequencyPenalty penalises frequently repeated tokens.
+	FrequencyPenalty *float64 `json:"frequency_penalty,omitempty"`
+	// PresencePenalty penalises tokens that have already appeared.
+	PresencePenalty *float64 `json:"presence_penalty,omitempty"`
+}
+
+// newOptions converts sampling parameters into Ollama options, returning nil
+// if none are set.
+func newOptions(p types.Params) *Options {
+	if p.IsZero() {
+		return nil
+	}
+	return &Options{
+		Temperature:      p.Temperature,
+		TopP:             p.TopP,
+		Seed:             p.Seed,
+		NumPredict:       p.MaxTokens,
+		Stop:             p.Stop,
+		FrequencyPenalty: p.FrequencyPenalty,
+		PresencePenalty:  p.PresencePenalty,
+	}
+}
+
+// ChatResponse represents a non-streaming /api/chat response.
+type ChatResponse struct {
+	// Model is the name of the model that completed the request.
+	Model string `json:"model"`
+	// Message is the generated message.
+	Message Message `json:"message"`
+	// PromptEvalCount is the number of tokens in the prompt.
+	PromptEvalCount int `json:"prompt_eval_count"`
+	// EvalCount is the number of tokens in the response.
+	EvalCount int `json:"eval_count"`
+	// EvalDuration is the time spent generating the response, in nanoseconds.
+	EvalDuration time.Duration `json:"eval_duration"`
+	// TotalDuration is the total time spent on the request, in nanoseconds.
+	TotalDuration time.Duration `json:"total_duration"`
+}
+
+// Complete sends a chat request with the schema as the structured output format.
+func (c *Client) Complete(ctx context.Context, r provider.Request) (*provider.CompletionResult, error) {
+	req := ChatRequest{
+		Model: r.Model,
+		Messages: []Message{
+			{Role: "system", Content: r.SystemPrompt},
+			{Role: "user", Content: r.UserInput},
+		},
+		Format:  r.Schema,
+		Options: newOptions(r.Params),
+	}
+
+	return provider.Retry(ctx, c.maxRetries, c.retryDelay, func() (*provider.CompletionResult, error) {
+		return c.doRequest(ctx, req)
+	})
+}
+
+func (c *Client) doRequest(ctx context.Context, chatReq ChatRequest) (*provider.CompletionResult, error) {
+	body, err := json.Marshal(chatReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/api/chat", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range c.headers {
+		req.Header.Set(k, v)
+	}
+
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	latency := time.Since(start)
+
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(respBody))
+	}
+
+	var chatResp ChatResponse
+	if err := json.Unmarshal(respBody, &chatResp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &provider.CompletionResult{
+		Response:       json.RawMessage(chatResp.Message.Content),
+		Provider:       "Ollama",
+		TokensIn:       chatResp.PromptEvalCount,
+		TokensOut:      chatResp.EvalCount,
+		Latency:        latency,
+		GenerationTime: chatResp.EvalDuration,
+		// Local models cost nothing to run
+		Cost: new(float64),
+	}, nil
+}
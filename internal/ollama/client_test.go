@@ -0,0 +1,113 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.carr.sh/litmus/internal/provider"
+	"go.carr.sh/litmus/internal/types"
+)
+
+func TestWithBaseURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"http://localhost:11434", "http://localhost:11434"},
+		{"http://localhost:11434/", "http://localhost:11434"},
+		{"127.0.0.1:11434", "http://127.0.0.1:11434"},
+		{"gpu-box:11434/", "http://gpu-box:11434"},
+		{"https://ollama.example.com", "https://ollama.example.com"},
+	}
+
+	for _, tt := range tests {
+		if got := NewClient(WithBaseURL(tt.url)).baseURL; got != tt.want {
+			t.Errorf("WithBaseURL(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestComplete(t *testing.T) {
+	const schema = `{"type":"object","properties":{"name":{"type":"string"}}}`
+
+	var got ChatRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			t.Errorf("path = %q, want /api/chat", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		json.NewEncoder(w).Encode(ChatResponse{
+			Message:         Message{Role: "assistant", Content: `{"name":"Ada"}`},
+			PromptEvalCount: 12,
+			EvalCount:       40,
+			EvalDuration:    2 * time.Second,
+			TotalDuration:   3 * time.Second,
+		})
+	}))
+	defer srv.Close()
+
+	temperature := 0.2
+	maxTokens := 256
+	// Pass the host without a scheme, as OLLAMA_HOST is usually written
+	c := NewClient(WithBaseURL(strings.TrimPrefix(srv.URL, "http://")), WithRetry(1, 0))
+	result, err := c.Complete(context.Background(), provider.Request{
+		Model:        "llama3.2",
+		SystemPrompt: "Extract the fields.",
+		UserInput:    "Ada Lovelace",
+		Schema:       json.RawMessage(schema),
+		Params:       types.Params{Temperature: &temperature, MaxTokens: &maxTokens},
+	})
+	if err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+
+	if got.Stream {
+		t.Error("stream = true, want false")
+	}
+	if string(got.Format) != schema {
+		t.Errorf("format = %s, want %s", got.Format, schema)
+	}
+	if got.Options == nil || *got.Options.Temperature != temperature || *got.Options.NumPredict != maxTokens {
+		t.Errorf("options = %+v, want temperature %v and num_predict %d", got.Options, temperature, maxTokens)
+	}
+	if len(got.Messages) != 2 || got.Messages[0].Content != "Extract the fields." || got.Messages[1].Content != "Ada Lovelace" {
+		t.Errorf("messages = %+v", got.Messages)
+	}
+
+	if string(result.Response) != `{"name":"Ada"}` {
+		t.Errorf("response = %s, want {\"name\":\"Ada\"}", result.Response)
+	}
+	if result.TokensIn != 12 || result.TokensOut != 40 {
+		t.Errorf("tokens = %d in, %d out, want 12 in, 40 out", result.TokensIn, result.TokensOut)
+	}
+	if result.GenerationTime != 2*time.Second {
+		t.Errorf("generation time = %v, want 2s", result.GenerationTime)
+	}
+	if result.Cost == nil || *result.Cost != 0 {
+		t.Errorf("cost = %v, want 0", result.Cost)
+	}
+}
+
+func TestCompleteWithoutParams(t *testing.T) {
+	var got map[string]json.RawMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		json.NewEncoder(w).Encode(ChatResponse{Message: Message{Content: `{}`}})
+	}))
+	defer srv.Close()
+
+	c := NewClient(WithBaseURL(srv.URL), WithRetry(1, 0))
+	if _, err := c.Complete(context.Background(), provider.Request{Model: "llama3.2", Schema: json.RawMessage(`{}`)}); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if _, ok := got["options"]; ok {
+		t.Errorf("options = %s, want none", got["options"])
+	}
+}
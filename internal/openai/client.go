@@ -0,0 +1,200 @@
+// Package openai provides an HTTP client for OpenAI-compatible chat completion
+// APIs, including self-hosted servers such as vLLM and llama.cpp.
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"go.carr.sh/litmus/internal/chat"
+	"go.carr.sh/litmus/internal/provider"
+)
+
+const (
+	defaultBaseURL    = "https://api.openai.com/v1"
+	defaultMaxRetries = 3
+	defaultRetryDelay = time.Second
+	defaultTimeout    = 120 * time.Second
+)
+
+// Response format variants understood by OpenAI-compatible servers.
+const (
+	// FormatJSONSchema sends the schema as a strict "json_schema" response
+	// format. This is the OpenAI default and is supported by vLLM.
+	FormatJSONSchema = "json_schema"
+	// FormatJSONObject sends a "json_object" response format with the schema
+	// attached, as understood by llama.cpp server.
+	FormatJSONObject = "json_object"
+	// FormatNone omits the response format and describes the schema in the
+	// system prompt instead, for servers without constrained decoding.
+	FormatNone = "none"
+)
+
+// ResponseFormats lists the valid response format variants.
+var ResponseFormats = []string{FormatJSONSchema, FormatJSONObject, FormatNone}
+
+// Client is an HTTP client for OpenAI-compatible APIs.
+type Client struct {
+	httpClient     *http.Client
+	apiKey         string
+	baseURL        string
+	headers        map[string]string
+	responseFormat string
+	maxRetries     int
+	retryDelay     time.Duration
+}
+
+var _ provider.Provider = (*Client)(nil)
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithBaseURL sets a custom base URL for the API, e.g. "http://localhost:8000/v1".
+func WithBaseURL(url string) Option {
+	return func(c *Client) {
+		c.baseURL = strings.TrimSuffix(url, "/")
+	}
+}
+
+// WithHTTPClient sets a custom HTTP client.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithRetry configures retry behavior.
+func WithRetry(maxRetries int, retryDelay time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.retryDelay = retryDelay
+	}
+}
+
+// WithTimeout sets the HTTP client timeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.httpClient.Timeout = timeout
+	}
+}
+
+// WithHeaders sets extra HTTP headers sent with every request.
+func WithHeaders(headers map[string]string) Option {
+	return func(c *Client) {
+		for k, v := range headers {
+			c.headers[k] = v
+		}
+	}
+}
+
+// WithResponseFormat sets the response format variant (see FormatJSONSchema,
+// FormatJSONObject and FormatNone).
+func WithResponseFormat(format string) Option {
+	return func(c *Client) {
+		c.responseFormat = format
+	}
+}
+
+// NewClient creates a new OpenAI-compatible API client. The API key may be
+// empty for servers that do not require authentication.
+func NewClient(apiKey string, opts ...Option) *Client {
+	c := &Client{
+		httpClient:     &http.Client{Timeout: defaultTimeout},
+		apiKey:         apiKey,
+		baseURL:        defaultBaseURL,
+		headers:        make(map[string]string),
+		responseFormat: FormatJSONSchema,
+		maxRetries:     defaultMaxRetries,
+		retryDelay:     defaultRetryDelay,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// ValidResponseFormat reports whether format is a known response format variant.
+func ValidResponseFormat(format string) bool {
+	return slices.Contains(ResponseFormats, format)
+}
+
+// Complete sends a chat completion request with structured output.
+func (c *Client) Complete(ctx context.Context, r provider.Request) (*provider.CompletionResult, error) {
+	req, err := c.buildRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return provider.Retry(ctx, c.maxRetries, c.retryDelay, func() (*provider.CompletionResult, error) {
+		return c.doRequest(ctx, req)
+	})
+}
+
+// buildRequest converts a provider request into a chat request using the
+// configured response format variant.
+func (c *Client) buildRequest(r provider.Request) (chat.Request, error) {
+	systemPrompt := r.SystemPrompt
+	var format *chat.ResponseFormat
+
+	switch c.responseFormat {
+	case FormatJSONSchema:
+		var err error
+		if format, err = chat.JSONSchemaFormat(r.Schema); err != nil {
+			return chat.Request{}, err
+		}
+	case FormatJSONObject:
+		format = &chat.ResponseFormat{Type: "json_object", Schema: r.Schema}
+		systemPrompt = withSchemaInstructions(systemPrompt, r.Schema)
+	case FormatNone:
+		systemPrompt = withSchemaInstructions(systemPrompt, r.Schema)
+	default:
+		return chat.Request{}, fmt.Errorf("unknown response format: %s (valid: %s)", c.responseFormat, strings.Join(ResponseFormats, ", "))
+	}
+
+	req := chat.NewRequest(r.Model, systemPrompt, r.UserInput, r.Params)
+	req.ResponseFormat = format
+	return req, nil
+}
+
+// doRequest sends a chat request and removes any code fence around the
+// response, which models sometimes add when the format is not enforced.
+func (c *Client) doRequest(ctx context.Context, req chat.Request) (*provider.CompletionResult, error) {
+	headers := make(map[string]string, len(c.headers)+1)
+	if c.apiKey != "" {
+		headers["Authorization"] = "Bearer " + c.apiKey
+	}
+	maps.Copy(headers, c.headers)
+
+	result, err := chat.Send(ctx, c.httpClient, c.baseURL, headers, req)
+	if err != nil {
+		return nil, err
+	}
+	result.Response = json.RawMessage(stripCodeFence(string(result.Response)))
+	return result, nil
+}
+
+// withSchemaInstructions appends the schema to the system prompt for response
+// formats that do not constrain the output to the schema themselves.
+func withSchemaInstructions(systemPrompt string, schema json.RawMessage) string {
+	return systemPrompt + "\n\nRespond only with a JSON object that conforms to this JSON schema:\n" + string(schema)
+}
+
+// stripCodeFence removes a surrounding Markdown code fence, which models
+// sometimes add when the output format is not enforced by the server.
+func stripCodeFence(content string) string {
+	trimmed := strings.TrimSpace(content)
+	if !strings.HasPrefix(trimmed, "```") || !strings.HasSuffix(trimmed, "```") {
+		return content
+	}
+	trimmed = strings.TrimSuffix(trimmed, "```")
+	if _, rest, ok := strings.Cut(trimmed, "\n"); ok {
+		return strings.TrimSpace(rest)
+	}
+	return content
+}
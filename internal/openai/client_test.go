@@ -0,0 +1,167 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.carr.sh/litmus/internal/chat"
+	"go.carr.sh/litmus/internal/provider"
+)
+
+func TestComplete(t *testing.T) {
+	const schema = `{"type":"object","properties":{"name":{"type":"string"}}}`
+
+	tests := []struct {
+		name   string
+		format string
+		// content is the message content returned by the stand-in server.
+		content string
+		// wantFormat is the response_format the client should send, or
+		// empty if it should be omitted.
+		wantFormat string
+		// wantInstructions is true if the schema should be described in
+		// the system prompt.
+		wantInstructions bool
+	}{
+		{
+			name:       "json_schema",
+			format:     FormatJSONSchema,
+			content:    `{"name":"Ada"}`,
+			wantFormat: `{"type":"json_schema","json_schema":{"name":"response","strict":true,"schema":` + schema + `}}`,
+		},
+		{
+			name:             "json_object",
+			format:           FormatJSONObject,
+			content:          `{"name":"Ada"}`,
+			wantFormat:       `{"type":"json_object","schema":` + schema + `}`,
+			wantInstructions: true,
+		},
+		{
+			name:             "none",
+			format:           FormatNone,
+			content:          "```json\n{\"name\":\"Ada\"}\n```",
+			wantInstructions: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got map[string]json.RawMessage
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/chat/completions" {
+					t.Errorf("path = %q, want /chat/completions", r.URL.Path)
+				}
+				if auth := r.Header.Get("Authorization"); auth != "Bearer test-key" {
+					t.Errorf("Authorization = %q, want Bearer test-key", auth)
+				}
+				if h := r.Header.Get("X-Team"); h != "evals" {
+					t.Errorf("X-Team = %q, want evals", h)
+				}
+				if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+					t.Errorf("failed to decode request: %v", err)
+				}
+				json.NewEncoder(w).Encode(chat.Response{
+					Choices: []chat.Choice{{Message: chat.Message{Role: "assistant", Content: tt.content}}},
+					Usage:   chat.Usage{PromptTokens: 12, CompletionTokens: 5},
+				})
+			}))
+			defer srv.Close()
+
+			c := NewClient("test-key",
+				WithBaseURL(srv.URL+"/"),
+				WithHeaders(map[string]string{"X-Team": "evals"}),
+				WithResponseFormat(tt.format),
+				WithRetry(1, 0))
+			result, err := c.Complete(context.Background(), provider.Request{
+				Model:        "gpt-test",
+				SystemPrompt: "Extract the fields.",
+				UserInput:    "Ada Lovelace",
+				Schema:       json.RawMessage(schema),
+			})
+			if err != nil {
+				t.Fatalf("Complete() error = %v", err)
+			}
+
+			format, ok := got["response_format"]
+			if tt.wantFormat == "" && ok {
+				t.Errorf("response_format = %s, want none", format)
+			}
+			if tt.wantFormat != "" {
+				assertJSON(t, "response_format", format, tt.wantFormat)
+			}
+
+			var messages []chat.Message
+			if err := json.Unmarshal(got["messages"], &messages); err != nil || len(messages) != 2 {
+				t.Fatalf("messages = %s, want a system and a user message", got["messages"])
+			}
+			if !strings.HasPrefix(messages[0].Content, "Extract the fields.") {
+				t.Errorf("system prompt = %q, want the prompt first", messages[0].Content)
+			}
+			if hasSchema := strings.Contains(messages[0].Content, schema); hasSchema != tt.wantInstructions {
+				t.Errorf("system prompt = %q, schema included = %v, want %v", messages[0].Content, hasSchema, tt.wantInstructions)
+			}
+			if messages[1].Content != "Ada Lovelace" {
+				t.Errorf("user message = %q, want Ada Lovelace", messages[1].Content)
+			}
+
+			assertJSON(t, "response", result.Response, `{"name":"Ada"}`)
+			if result.TokensIn != 12 || result.TokensOut != 5 {
+				t.Errorf("tokens = %d in, %d out, want 12 in, 5 out", result.TokensIn, result.TokensOut)
+			}
+			if result.Cost != nil {
+				t.Errorf("cost = %v, want unknown", *result.Cost)
+			}
+		})
+	}
+}
+
+func TestCompleteUnknownFormat(t *testing.T) {
+	c := NewClient("", WithResponseFormat("xml"))
+	if _, err := c.Complete(context.Background(), provider.Request{Schema: json.RawMessage(`{}`)}); err == nil {
+		t.Error("Complete() error = nil, want an error for an unknown response format")
+	}
+}
+
+func TestStripCodeFence(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"plain", `{"a":1}`, `{"a":1}`},
+		{"json fence", "```json\n{\"a\":1}\n```", `{"a":1}`},
+		{"bare fence", "```\n{\"a\":1}\n```", `{"a":1}`},
+		{"surrounding whitespace", "\n ```json\n{\"a\":1}\n```\n", `{"a":1}`},
+		{"unclosed fence", "```json\n{\"a\":1}", "```json\n{\"a\":1}"},
+		{"single line fence", "```{\"a\":1}```", "```{\"a\":1}```"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stripCodeFence(tt.content); got != tt.want {
+				t.Errorf("stripCodeFence(%q) = %q, want %q", tt.content, got, tt.want)
+			}
+		})
+	}
+}
+
+// assertJSON fails the test if got and want are not equal JSON values.
+func assertJSON(t *testing.T, name string, got json.RawMessage, want string) {
+	t.Helper()
+	var g, w any
+	if err := json.Unmarshal(got, &g); err != nil {
+		t.Fatalf("%s: invalid JSON %s: %v", name, got, err)
+	}
+	if err := json.Unmarshal([]byte(want), &w); err != nil {
+		t.Fatalf("%s: invalid expected JSON %s: %v", name, want, err)
+	}
+	gb, _ := json.Marshal(g)
+	wb, _ := json.Marshal(w)
+	if string(gb) != string(wb) {
+		t.Errorf("%s = %s, want %s", name, gb, wb)
+	}
+}
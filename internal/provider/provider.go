@@ -0,0 +1,76 @@
+// Package provider defines the interface between the runner and LLM backends.
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"go.carr.sh/litmus/internal/types"
+)
+
+// Provider sends structured completion requests to an LLM backend.
+type Provider interface {
+	// Complete sends a request and returns the model's structured response.
+	Complete(ctx context.Context, req Request) (*CompletionResult, error)
+}
+
+// Request is a single structured completion request.
+type Request struct {
+	// Model is the name of the model to use.
+	Model string `json:"model"`
+	// SystemPrompt is the system prompt sent to the model.
+	SystemPrompt string `json:"system_prompt"`
+	// UserInput is the user message sent to the model.
+	UserInput string `json:"user_input"`
+	// Schema is the JSON schema the response must conform to.
+	Schema json.RawMessage `json:"schema"`
+	// Params are the sampling parameters for the request.
+	Params types.Params `json:"params,omitzero"`
+	// Trial distinguishes repeated samples of the same request, so that
+	// response caches keep them apart. It is not sent to the model.
+	Trial int `json:"trial,omitempty"`
+}
+
+// Key returns a stable hash identifying the request, for use by layers that
+// store responses on disk.
+func (r Request) Key() string {
+	// Marshalling a struct of strings and valid JSON cannot fail, and
+	// compacts the schema so formatting changes don't alter the key.
+	data, _ := json.Marshal(r)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// CompletionResult contains the response and timing information.
+type CompletionResult struct {
+	// Response is the raw JSON response from the model.
+	Response json.RawMessage `json:"response"`
+	// Provider is the provider that completed the request.
+	Provider string `json:"provider,omitempty"`
+	// TokensIn is the number of tokens in the prompt.
+	TokensIn int `json:"tokens_in"`
+	// TokensOut is the number of tokens in the completion.
+	TokensOut int `json:"tokens_out"`
+	// CacheReadTokens is the number of prompt tokens read from the provider's prompt cache.
+	CacheReadTokens int `json:"cache_read_tokens,omitempty"`
+	// CacheWriteTokens is the number of prompt tokens written to the provider's prompt cache.
+	CacheWriteTokens int `json:"cache_write_tokens,omitempty"`
+	// Latency is the latency of the request.
+	Latency time.Duration `json:"latency_ns"`
+	// GenerationTime is the time the model spent generating output tokens,
+	// if reported by the provider. Unlike Latency it excludes network and
+	// prompt processing time.
+	GenerationTime time.Duration `json:"generation_ns,omitempty"`
+	// Cost is the cost of the request in USD, or nil if the provider does
+	// not report it. Local models and cached responses cost nothing.
+	Cost *float64 `json:"cost,omitempty"`
+	// Warnings are non-fatal problems with the request, such as schema
+	// keywords the provider does not support.
+	Warnings []string `json:"warnings,omitempty"`
+	// Cached is true if the response was served from the local response
+	// cache rather than the provider.
+	Cached bool `json:"cached,omitempty"`
+}
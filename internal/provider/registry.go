@@ -0,0 +1,116 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Factory creates a Provider. It is called at most once, the first time a
+// model routed to the provider is used.
+type Factory func() (Provider, error)
+
+// Registry routes requests to providers based on a model prefix such as
+// "openrouter:" or "ollama:". Models without a registered prefix are routed
+// to the fallback provider, so plain OpenRouter model names keep working.
+//
+// Registry implements Provider itself, so it can be handed to the runner
+// directly.
+type Registry struct {
+	mu        sync.Mutex
+	fallback  string
+	factories map[string]Factory
+	providers map[string]Provider
+}
+
+// NewRegistry creates an empty Registry that routes unprefixed models to the
+// provider registered under fallback.
+func NewRegistry(fallback string) *Registry {
+	return &Registry{
+		fallback:  fallback,
+		factories: make(map[string]Factory),
+		providers: make(map[string]Provider),
+	}
+}
+
+// Register adds a provider factory under the given prefix name (without the
+// trailing colon). Registering an existing name replaces it.
+func (r *Registry) Register(name string, factory Factory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.factories[name] = factory
+	delete(r.providers, name)
+}
+
+// Split separates a model string into the provider name and the model name
+// understood by that provider. Only registered prefixes are stripped, so
+// model names that legitimately contain a colon (e.g. "llama3:8b") are left
+// intact.
+func (r *Registry) Split(model string) (name, rest string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if prefix, rest, ok := strings.Cut(model, ":"); ok {
+		if _, exists := r.factories[prefix]; exists {
+			return prefix, rest
+		}
+	}
+	return r.fallback, model
+}
+
+// Get returns the provider registered under name, creating it if needed.
+func (r *Registry) Get(name string) (Provider, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if p, ok := r.providers[name]; ok {
+		return p, nil
+	}
+
+	factory, ok := r.factories[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown provider %q", name)
+	}
+
+	p, err := factory()
+	if err != nil {
+		return nil, fmt.Errorf("provider %s: %w", name, err)
+	}
+	r.providers[name] = p
+	return p, nil
+}
+
+// Resolve returns the provider for a model string along with the model name
+// to send to it.
+func (r *Registry) Resolve(model string) (Provider, string, error) {
+	name, rest := r.Split(model)
+	p, err := r.Get(name)
+	if err != nil {
+		return nil, "", err
+	}
+	return p, rest, nil
+}
+
+// Complete routes the request to the provider selected by its model prefix.
+// If the provider does not report who served the request, the provider name
+// is used instead.
+func (r *Registry) Complete(ctx context.Context, req Request) (*CompletionResult, error) {
+	name, model := r.Split(req.Model)
+	p, err := r.Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Model = model
+	result, err := p.Complete(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Provider == "" {
+		result.Provider = name
+	}
+	return result, nil
+}
@@ -0,0 +1,36 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Retry calls fn up to attempts times, waiting delay multiplied by the attempt
+// number between tries. It stops early if ctx is cancelled.
+func Retry(ctx context.Context, attempts int, delay time.Duration, fn func() (*CompletionResult, error)) (*CompletionResult, error) {
+	var lastErr error
+
+	for attempt := range attempts {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(delay * time.Duration(attempt)):
+			}
+		}
+
+		result, err := fn()
+		if err == nil {
+			return result, nil
+		}
+		lastErr = err
+
+		// Don't retry on context cancellation
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+	}
+
+	return nil, fmt.Errorf("failed after %d retries: %w", attempts, lastErr)
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"html/template"
 	"io"
+	"maps"
+	"slices"
 	"time"
 
 	"go.carr.sh/litmus/internal/types"
@@ -31,6 +33,56 @@ type templateData struct {
 	// GeneratedAt is the time the report was generated, which may differ from
 	// Report.Timestamp (when the tests were run) if reports are generated later.
 	GeneratedAt string
+	// HasTrials is true if any model ran tests more than once.
+	HasTrials bool
+	// HasLists is true if any model compared unordered array fields.
+	HasLists bool
+	// Heatmap is the accuracy of each field for each model, or nil if no
+	// fields were compared.
+	Heatmap *heatmap
+}
+
+// heatmap is a grid of field accuracy, with a row per field and a column per
+// model.
+type heatmap struct {
+	// Models are the column headings.
+	Models []string
+	// Rows are the fields, sorted by path.
+	Rows []heatmapRow
+}
+
+// heatmapRow is the accuracy of a single field for each model.
+type heatmapRow struct {
+	// Path is the path of the field.
+	Path string
+	// Cells are the field's accuracy for each model, or nil if the model's
+	// responses never included the field.
+	Cells []*types.FieldAccuracy
+}
+
+// buildHeatmap arranges the per-field accuracy of every model into a grid.
+func buildHeatmap(models []types.ModelRun) *heatmap {
+	rows := make(map[string][]*types.FieldAccuracy)
+	for i, mr := range models {
+		for _, fa := range mr.Metrics.FieldAccuracy {
+			if rows[fa.Path] == nil {
+				rows[fa.Path] = make([]*types.FieldAccuracy, len(models))
+			}
+			rows[fa.Path][i] = &fa
+		}
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	h := &heatmap{}
+	for _, mr := range models {
+		h.Models = append(h.Models, mr.Model)
+	}
+	for _, path := range slices.Sorted(maps.Keys(rows)) {
+		h.Rows = append(h.Rows, heatmapRow{Path: path, Cells: rows[path]})
+	}
+	return h
 }
 
 // Report outputs the complete run report as HTML.
@@ -44,6 +96,9 @@ func (h *HTML) Report(report *types.RunReport) error {
 			return string(b)
 		},
 		"formatDuration": formatDuration,
+		"formatDelta":    formatDelta,
+		"formatScore":    formatScore,
+		"formatKind":     formatKind,
 		"accuracyClass": func(acc float64) string {
 			if acc >= 90 {
 				return "success"
@@ -55,6 +110,13 @@ func (h *HTML) Report(report *types.RunReport) error {
 		"add": func(a, b int) int {
 			return a + b
 		},
+		"passedTrials": passedTrials,
+		"percent": func(f float64) string {
+			return fmt.Sprintf("%.0f%%", f*100)
+		},
+		"deref": func(b *bool) bool {
+			return b != nil && *b
+		},
 	}).Parse(htmlTemplate)
 	if err != nil {
 		return fmt.Errorf("failed to parse HTML template: %w", err)
@@ -63,6 +125,9 @@ func (h *HTML) Report(report *types.RunReport) error {
 	data := templateData{
 		Report:      report,
 		GeneratedAt: time.Now().Format(time.RFC3339),
+		HasTrials:   hasTrials(report.Models),
+		HasLists:    hasLists(report.Models),
+		Heatmap:     buildHeatmap(report.Models),
 	}
 
 	if err := tmpl.Execute(h.w, data); err != nil {
@@ -2,8 +2,11 @@
 package reporter
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
+	"strings"
 	"time"
 
 	"github.com/fatih/color"
@@ -50,6 +53,21 @@ func (t *Terminal) Report(report *types.RunReport) error {
 			fmt.Fprintf(t.w, "Provider: %s\n", provider)
 		}
 
+		if !modelRun.Params.IsZero() {
+			fmt.Fprintf(t.w, "Params:   %s\n", modelRun.Params)
+		}
+
+		for _, w := range modelRun.Warnings {
+			yellow.Fprintf(t.w, "Warning:  %s\n", w)
+		}
+
+		if len(modelRun.FixedSchema) > 0 {
+			fmt.Fprintf(t.w, "Schema:   rewritten for strict mode\n")
+			for _, line := range strings.Split(string(modelRun.FixedSchema), "\n") {
+				fmt.Fprintf(t.w, "          %s\n", line)
+			}
+		}
+
 		// Summary metrics
 		m := modelRun.Metrics
 		fmt.Fprintf(t.w, "Results:  ")
@@ -60,6 +78,10 @@ func (t *Terminal) Report(report *types.RunReport) error {
 		} else {
 			fmt.Fprintf(t.w, "%d failed", m.Failed)
 		}
+		if m.Invalid > 0 {
+			fmt.Fprintf(t.w, " / ")
+			red.Fprintf(t.w, "%d invalid", m.Invalid)
+		}
 		if m.Errors > 0 {
 			fmt.Fprintf(t.w, " / ")
 			yellow.Fprintf(t.w, "%d errors", m.Errors)
@@ -72,16 +94,48 @@ func (t *Terminal) Report(report *types.RunReport) error {
 		} else {
 			red.Fprintf(t.w, "%.1f%%", m.Accuracy)
 		}
-		fmt.Fprintf(t.w, " accuracy)\n")
+		fmt.Fprintf(t.w, " accuracy, %.1f%% field score)\n", m.MeanFieldScore)
 
-		fmt.Fprintf(t.w, "Tokens:   %d in / %d out\n", m.TotalTokensIn, m.TotalTokensOut)
+		if m.Trials > 0 {
+			fmt.Fprintf(t.w, "Trials:   %d per test (pass rate %.1f%%, pass@%d %.1f%%, pass^%d %.1f%%",
+				m.Trials, m.PassRate, m.Trials, m.PassAtK, m.Trials, m.PassHatK)
+			if m.Agreement > 0 {
+				fmt.Fprintf(t.w, ", agreement %.1f%%", m.Agreement)
+			}
+			fmt.Fprintf(t.w, ")\n")
+		}
+		for i, lm := range m.Lists {
+			label := "Lists:"
+			if i > 0 {
+				label = ""
+			}
+			fmt.Fprintf(t.w, "%-10s%s: P=%.1f%% R=%.1f%% F1=%.1f%% (macro F1 %.1f%%)\n",
+				label, lm.Path, lm.Precision, lm.Recall, lm.F1, lm.MacroF1)
+		}
+		if m.CacheHits > 0 {
+			fmt.Fprintf(t.w, "Cache:    %d of %d responses served from cache\n", m.CacheHits, m.TotalTests*max(m.Trials, 1))
+		}
+		fmt.Fprintf(t.w, "Tokens:   %d in / %d out", m.TotalTokensIn, m.TotalTokensOut)
+		if m.TotalCacheReadTokens > 0 || m.TotalCacheWriteTokens > 0 {
+			fmt.Fprintf(t.w, " (cache: %d read / %d write)", m.TotalCacheReadTokens, m.TotalCacheWriteTokens)
+		}
+		fmt.Fprintf(t.w, "\n")
+		if m.TotalCost > 0 {
+			fmt.Fprintf(t.w, "Cost:     $%.4f", m.TotalCost)
+			if m.JudgeCost > 0 {
+				fmt.Fprintf(t.w, " (judge: $%.4f)", m.JudgeCost)
+			}
+			fmt.Fprintf(t.w, "\n")
+		}
 		fmt.Fprintf(t.w, "Latency:  P50=%s  P95=%s  P99=%s\n",
 			formatDuration(m.LatencyP50),
 			formatDuration(m.LatencyP95),
 			formatDuration(m.LatencyP99))
-		fmt.Fprintf(t.w, "Duration: %s (%.1f tok/s)\n",
-			formatDuration(m.TotalDuration),
-			m.Throughput)
+		fmt.Fprintf(t.w, "Duration: %s (%.1f tok/s", formatDuration(m.TotalDuration), m.Throughput)
+		if m.GenerationThroughput > 0 {
+			fmt.Fprintf(t.w, ", %.1f tok/s generation", m.GenerationThroughput)
+		}
+		fmt.Fprintf(t.w, ")\n")
 		fmt.Fprintf(t.w, "\n")
 
 		// Test results table
@@ -90,6 +144,7 @@ func (t *Terminal) Report(report *types.RunReport) error {
 
 		// Show failures details
 		t.printFailureDetails(modelRun.Results)
+		t.printJudgements(modelRun.Results)
 	}
 
 	// Model comparison if multiple models
@@ -97,12 +152,16 @@ func (t *Terminal) Report(report *types.RunReport) error {
 		t.printComparisonTable(report.Models)
 	}
 
+	if report.GatesPassed != nil {
+		t.printGateSummary(report.Models, *report.GatesPassed)
+	}
+
 	return nil
 }
 
 func (t *Terminal) printResultsTable(results []types.TestResult) {
 	table := tablewriter.NewTable(t.w)
-	table.Header("Test", "Status", "Latency", "Tokens")
+	table.Header("Test", "Status", "Score", "Latency", "Tokens")
 
 	green := color.New(color.FgGreen).SprintFunc()
 	red := color.New(color.FgRed).SprintFunc()
@@ -112,6 +171,10 @@ func (t *Terminal) printResultsTable(results []types.TestResult) {
 		status := green("✓ PASS")
 		if r.Error != "" {
 			status = yellow("⚠ ERROR")
+		} else if len(r.SchemaViolations) > 0 && r.PassRate == 0 {
+			status = red("✗ INVALID")
+		} else if !r.Passed && r.PassRate > 0 {
+			status = yellow(fmt.Sprintf("◐ FLAKY %d/%d", passedTrials(r), len(r.Trials)))
 		} else if !r.Passed {
 			status = red("✗ FAIL")
 		}
@@ -121,8 +184,17 @@ func (t *Terminal) printResultsTable(results []types.TestResult) {
 			name = name[:37] + "..."
 		}
 
+		latency := formatDuration(r.Latency)
+		if r.Cached {
+			latency = "cached"
+		}
+
 		tokens := fmt.Sprintf("%d/%d", r.TokensIn, r.TokensOut)
-		table.Append(name, status, formatDuration(r.Latency), tokens)
+		score := fmt.Sprintf("%.0f%%", r.Score*100)
+		if r.Error != "" {
+			score = "-"
+		}
+		table.Append(name, status, score, latency, tokens)
 	}
 
 	table.Render()
@@ -152,15 +224,84 @@ func (t *Terminal) printFailureDetails(results []types.TestResult) {
 			yellow.Fprintf(t.w, "⚠ %s\n", r.TestName)
 			fmt.Fprintf(t.w, "  Error: %s\n\n", r.Error)
 		} else if !r.Passed {
-			red.Fprintf(t.w, "✗ %s\n", r.TestName)
+			red.Fprintf(t.w, "✗ %s", r.TestName)
+			if len(r.Trials) > 0 {
+				fmt.Fprintf(t.w, " (passed %d of %d trials)", passedTrials(r), len(r.Trials))
+			}
+			fmt.Fprintf(t.w, "\n")
+			for i, trial := range r.Trials {
+				if trial.Error != "" {
+					fmt.Fprintf(t.w, "  • Trial %d error: %s\n", i+1, trial.Error)
+				}
+			}
+			for _, v := range r.SchemaViolations {
+				fmt.Fprintf(t.w, "  • %s", v.Path)
+				red.Fprintf(t.w, " [schema: %s]", v.Keyword)
+				fmt.Fprintf(t.w, "\n    %s\n", v.Message)
+			}
 			for _, diff := range r.Diffs {
-				fmt.Fprintf(t.w, "  • %s\n", diff.Path)
-				fmt.Fprintf(t.w, "    Expected: %v\n", formatValue(diff.Expected))
-				fmt.Fprintf(t.w, "    Actual:   %v\n", formatValue(diff.Actual))
+				fmt.Fprintf(t.w, "  • %s", diff.Path)
+				if diff.Kind != "" && diff.Kind != types.DiffChanged {
+					yellow.Fprintf(t.w, " [%s]", formatKind(diff.Kind))
+				}
+				fmt.Fprintf(t.w, "\n")
+				fmt.Fprintf(t.w, "    Expected: %v\n", formatExpected(diff))
+				fmt.Fprintf(t.w, "    Actual:   %v\n", formatActual(diff))
+				if diff.Delta != nil {
+					fmt.Fprintf(t.w, "    Delta:    %s\n", formatDelta(diff.Delta))
+				}
+				if diff.Similarity != nil {
+					fmt.Fprintf(t.w, "    Similarity: %s\n", formatScore(diff.Similarity))
+				}
+				if diff.Message != "" {
+					fmt.Fprintf(t.w, "    Reason:   %s\n", diff.Message)
+				}
+				if diff.JudgeScore != nil {
+					fmt.Fprintf(t.w, "    Judge:    %s", formatScore(diff.JudgeScore))
+					if diff.Rationale != "" {
+						fmt.Fprintf(t.w, " (%s)", diff.Rationale)
+					}
+					fmt.Fprintf(t.w, "\n")
+				}
+			}
+			fmt.Fprintf(t.w, "\n")
+		}
+	}
+}
+
+// printJudgements prints the judge's verdict on every judged field, so that
+// the scores of fields that matched are shown as well as those that did not.
+func (t *Terminal) printJudgements(results []types.TestResult) {
+	green := color.New(color.FgGreen)
+	red := color.New(color.FgRed)
+
+	var judged bool
+	for _, r := range results {
+		if len(r.Judgements) == 0 {
+			continue
+		}
+		if !judged {
+			fmt.Fprintf(t.w, "Judged Fields:\n")
+			fmt.Fprintf(t.w, "%s\n", horizontalRule)
+			judged = true
+		}
+		fmt.Fprintf(t.w, "%s\n", r.TestName)
+		for _, j := range r.Judgements {
+			if j.Passed {
+				green.Fprintf(t.w, "  ✓")
+			} else {
+				red.Fprintf(t.w, "  ✗")
+			}
+			fmt.Fprintf(t.w, " %s: %s", j.Path, formatScore(&j.Score))
+			if j.Rationale != "" {
+				fmt.Fprintf(t.w, " (%s)", j.Rationale)
 			}
 			fmt.Fprintf(t.w, "\n")
 		}
 	}
+	if judged {
+		fmt.Fprintf(t.w, "\n")
+	}
 }
 
 func (t *Terminal) printComparisonTable(models []types.ModelRun) {
@@ -168,24 +309,83 @@ func (t *Terminal) printComparisonTable(models []types.ModelRun) {
 	bold.Fprintf(t.w, "Model Comparison\n")
 	fmt.Fprintf(t.w, "%s\n", horizontalRule)
 
+	trials := hasTrials(models)
+	lists := hasLists(models)
+
 	table := tablewriter.NewTable(t.w)
-	table.Header("Model", "Provider", "Accuracy", "P50 Latency", "Tok/s", "Tokens")
+	header := []any{"Model", "Provider", "Accuracy", "Field Score"}
+	if trials {
+		header = append(header, "Pass@k", "Agreement")
+	}
+	if lists {
+		header = append(header, "Precision", "Recall", "F1")
+	}
+	table.Header(append(header, "P50 Latency", "Tok/s", "Tokens")...)
 
 	for _, mr := range models {
 		m := mr.Metrics
-		table.Append(
+		row := []any{
 			util.Truncate(m.Model, 30),
 			getProvider(mr.Results),
 			fmt.Sprintf("%.1f%%", m.Accuracy),
+			fmt.Sprintf("%.1f%%", m.MeanFieldScore),
+		}
+		if trials {
+			row = append(row, formatPercent(m.PassAtK, m.Trials > 0), formatPercent(m.Agreement, m.Agreement > 0))
+		}
+		if lists {
+			lo := m.ListOverall
+			if lo == nil {
+				lo = &types.ListMetrics{}
+			}
+			ok := m.ListOverall != nil
+			row = append(row, formatPercent(lo.Precision, ok), formatPercent(lo.Recall, ok), formatPercent(lo.F1, ok))
+		}
+		table.Append(append(row,
 			formatDuration(m.LatencyP50),
 			fmt.Sprintf("%.1f", m.Throughput),
 			fmt.Sprintf("%d", m.TotalTokensIn+m.TotalTokensOut),
-		)
+		)...)
 	}
 
 	table.Render()
 }
 
+func (t *Terminal) printGateSummary(models []types.ModelRun, passed bool) {
+	bold := color.New(color.Bold)
+	green := color.New(color.FgGreen)
+	red := color.New(color.FgRed)
+
+	fmt.Fprintf(t.w, "\n")
+	bold.Fprintf(t.w, "Quality Gates\n")
+	fmt.Fprintf(t.w, "%s\n", horizontalRule)
+
+	for _, mr := range models {
+		if len(mr.Gates) == 0 {
+			continue
+		}
+		fmt.Fprintf(t.w, "%s\n", mr.Model)
+		for _, g := range mr.Gates {
+			if g.Passed {
+				green.Fprintf(t.w, "  ✓ %-16s", g.Gate)
+			} else {
+				red.Fprintf(t.w, "  ✗ %-16s", g.Gate)
+			}
+			fmt.Fprintf(t.w, "%s (threshold %s)", g.Actual, g.Threshold)
+			if g.Detail != "" {
+				fmt.Fprintf(t.w, " - %s", g.Detail)
+			}
+			fmt.Fprintf(t.w, "\n")
+		}
+	}
+
+	if passed {
+		green.Fprintf(t.w, "\nAll gates passed\n")
+	} else {
+		red.Fprintf(t.w, "\nOne or more gates breached\n")
+	}
+}
+
 func formatDuration(d time.Duration) string {
 	if d < time.Millisecond {
 		return fmt.Sprintf("%dµs", d.Microseconds())
@@ -196,17 +396,62 @@ func formatDuration(d time.Duration) string {
 	return fmt.Sprintf("%.2fs", d.Seconds())
 }
 
-func formatValue(v any) string {
-	if v == nil {
+// formatExpected formats the expected value of a diff, marking it as absent
+// for an unexpected field.
+func formatExpected(diff types.FieldDiff) string {
+	if diff.Kind == types.DiffUnexpected && diff.Expected == nil {
+		return "<absent>"
+	}
+	return formatValue(diff.Expected)
+}
+
+// formatActual formats the actual value of a diff, marking it as missing for
+// a missing field.
+func formatActual(diff types.FieldDiff) string {
+	if diff.Kind == types.DiffMissing && diff.Actual == nil {
 		return "<missing>"
 	}
+	return formatValue(diff.Actual)
+}
+
+// formatKind formats a diff kind for display, e.g. "type mismatch".
+func formatKind(kind string) string {
+	return strings.ReplaceAll(kind, "_", " ")
+}
+
+// formatValue formats a value as JSON, so that strings are quoted and a
+// type mismatch such as 25 and "25" is visible.
+func formatValue(v any) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
 	s := fmt.Sprintf("%v", v)
+	if err := enc.Encode(v); err == nil {
+		s = strings.TrimSuffix(buf.String(), "\n")
+	}
 	if len(s) > 60 {
 		return s[:57] + "..."
 	}
 	return s
 }
 
+// formatDelta formats a numeric difference with its sign.
+func formatDelta(d *float64) string {
+	if d == nil {
+		return ""
+	}
+	return fmt.Sprintf("%+.6g", *d)
+}
+
+// formatScore formats a score from 0 to 1, such as a string similarity or
+// a judge score.
+func formatScore(s *float64) string {
+	if s == nil {
+		return ""
+	}
+	return fmt.Sprintf("%.2f", *s)
+}
+
 // getProvider extracts the provider from test results (returns first non-empty provider found).
 func getProvider(results []types.TestResult) string {
 	for _, r := range results {
@@ -216,3 +461,42 @@ func getProvider(results []types.TestResult) string {
 	}
 	return ""
 }
+
+// formatPercent formats a percentage, or "-" if it is not set.
+func formatPercent(v float64, ok bool) string {
+	if !ok {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", v)
+}
+
+// passedTrials returns the number of trials of a repeated test that passed.
+func passedTrials(r types.TestResult) int {
+	n := 0
+	for _, t := range r.Trials {
+		if t.Passed {
+			n++
+		}
+	}
+	return n
+}
+
+// hasTrials reports whether any model ran its tests more than once.
+func hasTrials(models []types.ModelRun) bool {
+	for _, mr := range models {
+		if mr.Metrics.Trials > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// hasLists reports whether any model compared unordered array fields.
+func hasLists(models []types.ModelRun) bool {
+	for _, mr := range models {
+		if mr.Metrics.ListOverall != nil {
+			return true
+		}
+	}
+	return false
+}
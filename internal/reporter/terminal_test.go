@@ -0,0 +1,85 @@
+package reporter
+
+import (
+	"strings"
+	"testing"
+
+	"go.carr.sh/litmus/internal/types"
+)
+
+func TestFormatDelta(t *testing.T) {
+	delta := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name string
+		d    *float64
+		want string
+	}{
+		{"none", nil, ""},
+		{"positive", delta(0.02), "+0.02"},
+		{"negative", delta(-2.5), "-2.5"},
+		{"zero", delta(0), "+0"},
+		{"large", delta(12345678), "+1.23457e+07"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatDelta(tt.d); got != tt.want {
+				t.Errorf("formatDelta() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	tests := []struct {
+		name string
+		v    any
+		want string
+	}{
+		{"null", nil, "null"},
+		{"number", 25.0, "25"},
+		{"large number", 1e6, "1000000"},
+		{"string", "25", `"25"`},
+		{"string with markup", "<a & b>", `"<a & b>"`},
+		{"bool", true, "true"},
+		{"object", map[string]any{"$gte": 18.0}, `{"$gte":18}`},
+		{"array", []any{"a", 1.0}, `["a",1]`},
+		{"long string", strings.Repeat("x", 70), `"` + strings.Repeat("x", 56) + "..."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatValue(tt.v); got != tt.want {
+				t.Errorf("formatValue(%v) = %s, want %s", tt.v, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatExpectedAndActual(t *testing.T) {
+	tests := []struct {
+		name         string
+		diff         types.FieldDiff
+		wantExpected string
+		wantActual   string
+	}{
+		{"type mismatch", types.FieldDiff{Kind: types.DiffTypeMismatch, Expected: 25.0, Actual: "25"}, "25", `"25"`},
+		{"explicit null", types.FieldDiff{Kind: types.DiffTypeMismatch, Expected: "x"}, `"x"`, "null"},
+		{"missing", types.FieldDiff{Kind: types.DiffMissing, Expected: "x"}, `"x"`, "<missing>"},
+		{"missing null", types.FieldDiff{Kind: types.DiffMissing}, "null", "<missing>"},
+		{"unexpected", types.FieldDiff{Kind: types.DiffUnexpected, Actual: 1.0}, "<absent>", "1"},
+		{"unexpected null", types.FieldDiff{Kind: types.DiffUnexpected}, "<absent>", "null"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatExpected(tt.diff); got != tt.wantExpected {
+				t.Errorf("formatExpected() = %s, want %s", got, tt.wantExpected)
+			}
+			if got := formatActual(tt.diff); got != tt.wantActual {
+				t.Errorf("formatActual() = %s, want %s", got, tt.wantActual)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package runner
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+
+	"go.carr.sh/litmus/internal/compare"
+	"go.carr.sh/litmus/internal/types"
+)
+
+// missingLabel is the label of an enum field that is absent from a response.
+const missingLabel = "(missing)"
+
+// confusionMatrices builds a confusion matrix for each enum field that has
+// an expected value in at least one test. Every trial of a repeated test is
+// counted, and tests that errored are skipped.
+func confusionMatrices(enums map[string][]any, results []types.TestResult) []types.ConfusionMatrix {
+	if len(enums) == 0 {
+		return nil
+	}
+
+	// pairs holds the expected and actual label of every comparison, by path
+	pairs := make(map[string][][2]string)
+	for _, r := range results {
+		samples := r.Trials
+		if len(samples) == 0 {
+			samples = []types.TestResult{r}
+		}
+
+		for _, s := range samples {
+			if s.Error != "" {
+				continue
+			}
+			var expected, actual any
+			if json.Unmarshal(s.Expected, &expected) != nil || json.Unmarshal(s.Actual, &actual) != nil {
+				continue
+			}
+
+			actualLeaves := compare.Flatten(actual)
+			for path, e := range compare.Flatten(expected) {
+				key := compare.NormalizePath(path)
+				if _, ok := enums[key]; !ok {
+					continue
+				}
+				a := missingLabel
+				if v, ok := actualLeaves[path]; ok {
+					a = label(v)
+				}
+				pairs[key] = append(pairs[key], [2]string{label(e), a})
+			}
+		}
+	}
+
+	var matrices []types.ConfusionMatrix
+	for _, path := range slices.Sorted(maps.Keys(pairs)) {
+		matrices = append(matrices, confusionMatrix(path, enums[path], pairs[path]))
+	}
+	return matrices
+}
+
+// confusionMatrix tallies the expected and actual labels of an enum field
+// and derives its per-class metrics and Cohen's kappa.
+func confusionMatrix(path string, values []any, pairs [][2]string) types.ConfusionMatrix {
+	var labels []string
+	for _, v := range values {
+		labels = append(labels, label(v))
+	}
+	var extra []string
+	for _, p := range pairs {
+		for _, l := range p {
+			if !slices.Contains(labels, l) && !slices.Contains(extra, l) {
+				extra = append(extra, l)
+			}
+		}
+	}
+	slices.Sort(extra)
+	labels = append(labels, extra...)
+
+	counts := make([][]int, len(labels))
+	for i := range counts {
+		counts[i] = make([]int, len(labels))
+	}
+	for _, p := range pairs {
+		counts[slices.Index(labels, p[0])][slices.Index(labels, p[1])]++
+	}
+
+	rows := make([]int, len(labels))
+	cols := make([]int, len(labels))
+	var agreed int
+	for i := range labels {
+		for j := range labels {
+			rows[i] += counts[i][j]
+			cols[j] += counts[i][j]
+		}
+		agreed += counts[i][i]
+	}
+
+	m := types.ConfusionMatrix{Path: path, Labels: labels, Counts: counts}
+	for i, l := range labels {
+		c := types.ClassMetrics{Label: l, Support: rows[i]}
+		if cols[i] > 0 {
+			c.Precision = float64(counts[i][i]) / float64(cols[i]) * 100
+		}
+		if rows[i] > 0 {
+			c.Recall = float64(counts[i][i]) / float64(rows[i]) * 100
+		}
+		if c.Precision+c.Recall > 0 {
+			c.F1 = 2 * c.Precision * c.Recall / (c.Precision + c.Recall)
+		}
+		m.Classes = append(m.Classes, c)
+	}
+
+	// Kappa compares the observed agreement with the agreement expected by
+	// chance given how often each label was expected and returned
+	n := float64(len(pairs))
+	observed := float64(agreed) / n
+	var chance float64
+	for i := range labels {
+		chance += float64(rows[i]) * float64(cols[i]) / (n * n)
+	}
+	if chance < 1 {
+		m.Kappa = (observed - chance) / (1 - chance)
+	} else if observed == 1 {
+		m.Kappa = 1
+	}
+
+	return m
+}
+
+// label returns the label of an enum value: strings as is and other values
+// JSON-encoded.
+func label(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
@@ -0,0 +1,101 @@
+package runner
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"go.carr.sh/litmus/internal/types"
+)
+
+// repeat returns n copies of an expected and actual label pair.
+func repeat(expected, actual string, n int) [][2]string {
+	pairs := make([][2]string, n)
+	for i := range pairs {
+		pairs[i] = [2]string{expected, actual}
+	}
+	return pairs
+}
+
+func TestConfusionMatrix(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []any
+		pairs  [][2]string
+		// wantLabels are the labels of the matrix.
+		wantLabels []string
+		// wantCounts are the counts of the matrix.
+		wantCounts [][]int
+		// wantClasses are the support, precision and recall of each class.
+		wantClasses []types.ClassMetrics
+		// wantKappa is Cohen's kappa.
+		wantKappa float64
+	}{
+		{
+			name:   "two classes",
+			values: []any{"a", "b"},
+			pairs: slices.Concat(
+				repeat("a", "a", 20), repeat("a", "b", 5),
+				repeat("b", "a", 10), repeat("b", "b", 15),
+			),
+			wantLabels: []string{"a", "b"},
+			wantCounts: [][]int{{20, 5}, {10, 15}},
+			wantClasses: []types.ClassMetrics{
+				{Label: "a", Support: 25, Precision: 200.0 / 3, Recall: 80},
+				{Label: "b", Support: 25, Precision: 75, Recall: 60},
+			},
+			wantKappa: 0.4,
+		},
+		{
+			name:       "perfect agreement on one label",
+			values:     []any{"a", "b"},
+			pairs:      repeat("a", "a", 3),
+			wantLabels: []string{"a", "b"},
+			wantCounts: [][]int{{3, 0}, {0, 0}},
+			wantClasses: []types.ClassMetrics{
+				{Label: "a", Support: 3, Precision: 100, Recall: 100},
+				{Label: "b"},
+			},
+			wantKappa: 1,
+		},
+		{
+			name:       "missing and unexpected values",
+			values:     []any{true, false},
+			pairs:      [][2]string{{"true", "true"}, {"false", missingLabel}, {"true", "maybe"}},
+			wantLabels: []string{"true", "false", missingLabel, "maybe"},
+			wantCounts: [][]int{{1, 0, 0, 1}, {0, 0, 1, 0}, {0, 0, 0, 0}, {0, 0, 0, 0}},
+			wantClasses: []types.ClassMetrics{
+				{Label: "true", Support: 2, Precision: 100, Recall: 50},
+				{Label: "false", Support: 1},
+				{Label: missingLabel},
+				{Label: "maybe"},
+			},
+			wantKappa: 1.0 / 7,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := confusionMatrix("status", tt.values, tt.pairs)
+			if !slices.Equal(m.Labels, tt.wantLabels) {
+				t.Errorf("Labels = %v, want %v", m.Labels, tt.wantLabels)
+			}
+			if !slices.EqualFunc(m.Counts, tt.wantCounts, slices.Equal[[]int]) {
+				t.Errorf("Counts = %v, want %v", m.Counts, tt.wantCounts)
+			}
+			if math.Abs(m.Kappa-tt.wantKappa) > 1e-9 {
+				t.Errorf("Kappa = %v, want %v", m.Kappa, tt.wantKappa)
+			}
+			if len(m.Classes) != len(tt.wantClasses) {
+				t.Fatalf("Classes = %+v, want %+v", m.Classes, tt.wantClasses)
+			}
+			for i, want := range tt.wantClasses {
+				got := m.Classes[i]
+				if got.Label != want.Label || got.Support != want.Support ||
+					math.Abs(got.Precision-want.Precision) > 1e-9 || math.Abs(got.Recall-want.Recall) > 1e-9 {
+					t.Errorf("Classes[%d] = %+v, want %+v", i, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package runner
+
+import (
+	"maps"
+	"slices"
+	"time"
+
+	"go.carr.sh/litmus/internal/types"
+)
+
+// calculateMetrics computes aggregated metrics from test results.
+func calculateMetrics(model string, results []types.TestResult, totalDuration time.Duration) types.ModelMetrics {
+	metrics := types.ModelMetrics{
+		Model:         model,
+		TotalTests:    len(results),
+		TotalDuration: totalDuration,
+	}
+
+	var latencies []time.Duration
+	var generationTime time.Duration
+	var generatedTokens int
+	var liveTokensOut int
+	var agreed int
+	fields := make(map[string]types.FieldCount)
+	lists := make(map[string]*listTally)
+	overall := &listTally{}
+
+	for _, r := range results {
+		if r.Error != "" {
+			metrics.Errors++
+		} else if len(r.SchemaViolations) > 0 {
+			metrics.Invalid++
+		} else if r.Passed {
+			metrics.Passed++
+		} else {
+			metrics.Failed++
+		}
+
+		metrics.MeanFieldScore += r.Score
+
+		if len(r.Trials) > 0 {
+			metrics.Trials = len(r.Trials)
+			metrics.PassRate += r.PassRate
+			if r.PassRate > 0 {
+				metrics.PassAtK++
+			}
+			if r.Agreement > 0 {
+				metrics.Agreement += r.Agreement
+				agreed++
+			}
+		}
+
+		// Token, cache and latency metrics are per request, so count every trial
+		samples := r.Trials
+		if len(samples) == 0 {
+			samples = []types.TestResult{r}
+		}
+
+		for _, s := range samples {
+			for path, fc := range s.Fields {
+				total := fields[path]
+				total.Matched += fc.Matched
+				total.Total += fc.Total
+				fields[path] = total
+			}
+			for path, lc := range s.Lists {
+				if lists[path] == nil {
+					lists[path] = &listTally{}
+				}
+				lists[path].add(lc)
+				overall.add(lc)
+			}
+
+			metrics.TotalTokensIn += s.TokensIn
+			metrics.TotalTokensOut += s.TokensOut
+			metrics.TotalCacheReadTokens += s.CacheReadTokens
+			metrics.TotalCacheWriteTokens += s.CacheWriteTokens
+			if s.Cost != nil {
+				metrics.TotalCost += *s.Cost
+			} else if s.Error == "" {
+				metrics.UnpricedResponses++
+			}
+			for _, j := range s.Judgements {
+				if j.Cost != nil {
+					metrics.JudgeCost += *j.Cost
+					metrics.TotalCost += *j.Cost
+				} else {
+					metrics.UnpricedResponses++
+				}
+			}
+
+			// Cached responses would skew latency and throughput towards zero
+			if s.Cached {
+				metrics.CacheHits++
+				continue
+			}
+
+			liveTokensOut += s.TokensOut
+
+			if s.Latency > 0 {
+				latencies = append(latencies, s.Latency)
+			}
+
+			if s.GenerationTime > 0 {
+				generationTime += s.GenerationTime
+				generatedTokens += s.TokensOut
+			}
+		}
+	}
+
+	if metrics.TotalTests > 0 {
+		metrics.Accuracy = float64(metrics.Passed) / float64(metrics.TotalTests) * 100
+		metrics.MeanFieldScore = metrics.MeanFieldScore / float64(metrics.TotalTests) * 100
+	}
+
+	if metrics.Trials > 0 {
+		metrics.PassRate = metrics.PassRate / float64(metrics.TotalTests) * 100
+		metrics.PassAtK = metrics.PassAtK / float64(metrics.TotalTests) * 100
+		metrics.PassHatK = metrics.Accuracy
+	}
+
+	for _, path := range slices.Sorted(maps.Keys(fields)) {
+		fc := fields[path]
+		metrics.FieldAccuracy = append(metrics.FieldAccuracy, types.FieldAccuracy{
+			Path:       path,
+			FieldCount: fc,
+			Accuracy:   float64(fc.Matched) / float64(fc.Total) * 100,
+		})
+	}
+
+	for _, path := range slices.Sorted(maps.Keys(lists)) {
+		lm := lists[path].metrics()
+		lm.Path = path
+		metrics.Lists = append(metrics.Lists, lm)
+	}
+	if overall.n > 0 {
+		lm := overall.metrics()
+		metrics.ListOverall = &lm
+	}
+
+	if agreed > 0 {
+		metrics.Agreement = metrics.Agreement / float64(agreed) * 100
+	}
+
+	if totalDuration > 0 {
+		metrics.Throughput = float64(liveTokensOut) / totalDuration.Seconds()
+	}
+
+	if generationTime > 0 {
+		metrics.GenerationThroughput = float64(generatedTokens) / generationTime.Seconds()
+	}
+
+	// Calculate latency percentiles
+	if len(latencies) > 0 {
+		slices.Sort(latencies)
+
+		metrics.LatencyP50 = percentile(latencies, 50)
+		metrics.LatencyP95 = percentile(latencies, 95)
+		metrics.LatencyP99 = percentile(latencies, 99)
+	}
+
+	return metrics
+}
+
+// listTally accumulates the set counts of array fields for micro averages,
+// and the per-test scores for macro averages.
+type listTally struct {
+	// count is the pooled count.
+	count types.ListCount
+	// precision, recall and f1 are the sums of the per-test scores.
+	precision, recall, f1 float64
+	// n is the number of counts added.
+	n int
+}
+
+// add adds the count of one array field of one test.
+func (t *listTally) add(lc types.ListCount) {
+	t.count = t.count.Add(lc)
+	p, r, f1 := lc.Scores()
+	t.precision += p
+	t.recall += r
+	t.f1 += f1
+	t.n++
+}
+
+// metrics returns the micro and macro averages as percentages.
+func (t *listTally) metrics() types.ListMetrics {
+	p, r, f1 := t.count.Scores()
+	n := float64(t.n)
+	return types.ListMetrics{
+		ListCount:      t.count,
+		Precision:      p * 100,
+		Recall:         r * 100,
+		F1:             f1 * 100,
+		MacroPrecision: t.precision / n * 100,
+		MacroRecall:    t.recall / n * 100,
+		MacroF1:        t.f1 / n * 100,
+	}
+}
+
+// percentile calculates the p-th percentile of a sorted slice.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+
+	idx := float64(p) / 100.0 * float64(len(sorted)-1)
+	lower := int(idx)
+	upper := lower + 1
+
+	if upper >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+
+	weight := idx - float64(lower)
+	return time.Duration(float64(sorted[lower])*(1-weight) + float64(sorted[upper])*weight)
+}
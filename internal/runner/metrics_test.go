@@ -0,0 +1,96 @@
+package runner
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"go.carr.sh/litmus/internal/types"
+)
+
+func TestListTally(t *testing.T) {
+	var tally listTally
+	// One perfect test and one that found nothing pool into a micro average
+	// dominated by the larger test, but a macro average of the two scores
+	tally.add(types.ListCount{TruePositives: 1})
+	tally.add(types.ListCount{FalsePositives: 1, FalseNegatives: 3})
+	m := tally.metrics()
+
+	want := types.ListMetrics{
+		ListCount:      types.ListCount{TruePositives: 1, FalsePositives: 1, FalseNegatives: 3},
+		Precision:      50,
+		Recall:         25,
+		F1:             100.0 / 3,
+		MacroPrecision: 50,
+		MacroRecall:    50,
+		MacroF1:        50,
+	}
+	if m.ListCount != want.ListCount {
+		t.Errorf("ListCount = %+v, want %+v", m.ListCount, want.ListCount)
+	}
+	for _, f := range []struct {
+		name      string
+		got, want float64
+	}{
+		{"Precision", m.Precision, want.Precision},
+		{"Recall", m.Recall, want.Recall},
+		{"F1", m.F1, want.F1},
+		{"MacroPrecision", m.MacroPrecision, want.MacroPrecision},
+		{"MacroRecall", m.MacroRecall, want.MacroRecall},
+		{"MacroF1", m.MacroF1, want.MacroF1},
+	} {
+		if math.Abs(f.got-f.want) > 1e-9 {
+			t.Errorf("%s = %v, want %v", f.name, f.got, f.want)
+		}
+	}
+}
+
+func TestGenerationThroughput(t *testing.T) {
+	results := []types.TestResult{
+		{Passed: true, TokensOut: 40, Latency: 3 * time.Second, GenerationTime: 2 * time.Second},
+		{Passed: true, TokensOut: 20, Latency: 2 * time.Second, GenerationTime: time.Second},
+		// Providers that don't report generation time are left out
+		{Passed: true, TokensOut: 100, Latency: time.Second},
+	}
+
+	m := calculateMetrics("llama3.2", results, 10*time.Second)
+	if m.GenerationThroughput != 20 {
+		t.Errorf("GenerationThroughput = %v, want 20 tokens/s", m.GenerationThroughput)
+	}
+	if m.Throughput != 16 {
+		t.Errorf("Throughput = %v, want 16 tokens/s", m.Throughput)
+	}
+}
+
+func TestJudgeCost(t *testing.T) {
+	cost := func(v float64) *float64 { return &v }
+	results := []types.TestResult{
+		{Passed: true, Cost: cost(0.01), Judgements: []types.Judgement{
+			{Path: "summary", Score: 0.9, Passed: true, Cost: cost(0.002)},
+			{Path: "title", Score: 0.8, Passed: true, Cost: cost(0)},
+		}},
+		// Repeated tests pay for the judge on every trial
+		aggregateTrials([]types.TestResult{
+			{Passed: true, Cost: cost(0.01), Judgements: []types.Judgement{{Path: "summary", Score: 1, Passed: true, Cost: cost(0.001)}}},
+			{Cost: cost(0.01), Judgements: []types.Judgement{{Path: "summary", Score: 0.2, Cost: nil}}},
+		}),
+	}
+
+	m := calculateMetrics("gpt-test", results, 0)
+	if math.Abs(m.JudgeCost-0.003) > 1e-9 {
+		t.Errorf("JudgeCost = %v, want 0.003", m.JudgeCost)
+	}
+	if math.Abs(m.TotalCost-0.033) > 1e-9 {
+		t.Errorf("TotalCost = %v, want 0.033", m.TotalCost)
+	}
+	if m.UnpricedResponses != 1 {
+		t.Errorf("UnpricedResponses = %d, want 1", m.UnpricedResponses)
+	}
+
+	// The judge's spend counts towards the cost gate
+	limit := 0.011
+	m = calculateMetrics("gpt-test", results[:1], 0)
+	if r := (types.Gates{MaxCost: &limit}).Evaluate(m)[0]; r.Passed || r.Actual != "$0.0120" {
+		t.Errorf("max_cost gate = %+v, want breached at $0.0120", r)
+	}
+}
@@ -4,52 +4,75 @@ package runner
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"slices"
 	"sync"
 	"time"
 
+	"go.carr.sh/litmus/internal/cassette"
 	"go.carr.sh/litmus/internal/compare"
-	"go.carr.sh/litmus/internal/openrouter"
+	"go.carr.sh/litmus/internal/jsonschema"
+	"go.carr.sh/litmus/internal/judge"
+	"go.carr.sh/litmus/internal/provider"
 	"go.carr.sh/litmus/internal/types"
 )
 
 // Runner executes tests against LLM models.
 type Runner struct {
-	// client is the OpenRouter client.
-	client *openrouter.Client
+	// provider is the backend that completes requests.
+	provider provider.Provider
 	// parallel is the number of parallel requests per model.
 	parallel int
+	// repeat is the number of times each test is run.
+	repeat int
+	// compareOpts configure how responses are compared with expected values.
+	compareOpts compare.Options
+	// judge scores fields at judged paths, sending requests through provider.
+	judge *judge.Judge
 }
 
-// New creates a new Runner.
-func New(apiKey string, parallel int) *Runner {
-	if parallel < 1 {
-		parallel = 1
-	}
-	return &Runner{
-		client:   openrouter.NewClient(apiKey),
-		parallel: parallel,
+// Option configures a Runner.
+type Option func(*Runner)
+
+// WithRepeat runs each test n times, so that consistency across samples can
+// be measured.
+func WithRepeat(n int) Option {
+	return func(r *Runner) {
+		if n > 0 {
+			r.repeat = n
+		}
 	}
 }
 
-// LoadTestFile loads test cases from a JSON file.
-func LoadTestFile(path string) ([]types.TestCase, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read test file: %w", err)
+// WithCompareOptions sets the options used to compare responses with
+// expected values.
+func WithCompareOptions(opts compare.Options) Option {
+	return func(r *Runner) {
+		r.compareOpts = opts
 	}
+}
 
-	var tests []types.TestCase
-	if err := json.Unmarshal(data, &tests); err != nil {
-		return nil, fmt.Errorf("failed to parse test file: %w", err)
+// New creates a new Runner that sends requests to p.
+func New(p provider.Provider, parallel int, opts ...Option) *Runner {
+	if parallel < 1 {
+		parallel = 1
 	}
-
-	return tests, nil
+	r := &Runner{
+		provider: p,
+		parallel: parallel,
+		repeat:   1,
+		judge:    judge.New(p),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
 }
 
-// LoadSchema loads a JSON schema from a file.
+// LoadSchema loads a JSON schema from a file. Parse errors are returned as a
+// *ParseError giving the line.
 func LoadSchema(path string) (json.RawMessage, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -59,15 +82,29 @@ func LoadSchema(path string) (json.RawMessage, error) {
 	// Validate it's valid JSON
 	var v any
 	if err := json.Unmarshal(data, &v); err != nil {
-		return nil, fmt.Errorf("invalid JSON in schema file: %w", err)
+		return nil, fmt.Errorf("invalid JSON in schema file: %w", jsonError(data, 0, err))
 	}
 
 	return json.RawMessage(data), nil
 }
 
-// Run executes all test cases against a model and returns results.
-func (r *Runner) Run(ctx context.Context, model, prompt string, schema json.RawMessage, tests []types.TestCase) *types.ModelRun {
-	results := make([]types.TestResult, len(tests))
+// Run executes all test cases against a model with the given sampling
+// parameters and returns results. When replaying a cassette, requests that
+// were never recorded fail the whole run rather than single tests: the
+// returned error wraps cassette.ErrMiss and names every missing request.
+func (r *Runner) Run(ctx context.Context, model string, params types.Params, prompt string, schema json.RawMessage, tests []types.TestCase) (*types.ModelRun, error) {
+	trials := make([][]types.TestResult, len(tests))
+	warnings := make([][]string, len(tests)*r.repeat)
+	misses := make([]error, len(tests)*r.repeat)
+
+	// Responses are validated locally, as some providers do not enforce the
+	// schema they are given
+	var runWarnings []string
+	validator, err := jsonschema.Compile(schema)
+	if err != nil {
+		runWarnings = append(runWarnings, fmt.Sprintf("responses not validated against the schema: %v", err))
+	}
+
 	startTime := time.Now()
 
 	// Create a semaphore for parallel execution
@@ -75,125 +112,118 @@ func (r *Runner) Run(ctx context.Context, model, prompt string, schema json.RawM
 	var wg sync.WaitGroup
 
 	for i, tc := range tests {
-		wg.Add(1)
-		go func(idx int, test types.TestCase) {
-			defer wg.Done()
-
-			sem <- struct{}{}        // Acquire
-			defer func() { <-sem }() // Release
-
-			results[idx] = r.runSingleTest(ctx, model, prompt, schema, test)
-		}(i, tc)
+		trials[i] = make([]types.TestResult, r.repeat)
+		for trial := range r.repeat {
+			wg.Add(1)
+			go func(idx, trial int, test types.TestCase) {
+				defer wg.Done()
+
+				sem <- struct{}{}        // Acquire
+				defer func() { <-sem }() // Release
+
+				i := idx*r.repeat + trial
+				trials[idx][trial], warnings[i], misses[i] = r.runSingleTest(ctx, model, params, prompt, schema, validator, test, trial)
+			}(i, trial, tc)
+		}
 	}
 
 	wg.Wait()
 	totalDuration := time.Since(startTime)
 
+	if err := errors.Join(misses...); err != nil {
+		return nil, err
+	}
+
+	results := make([]types.TestResult, len(tests))
+	for i := range tests {
+		results[i] = aggregateTrials(trials[i])
+	}
+
 	metrics := calculateMetrics(model, results, totalDuration)
 
+	// A schema that is not an object has no enum fields to tally
+	enums, _ := jsonschema.Enums(schema)
+	metrics.Confusion = confusionMatrices(enums, results)
+
 	return &types.ModelRun{
-		Model:   model,
-		Results: results,
-		Metrics: metrics,
-	}
+		Model:    model,
+		Params:   params,
+		Results:  results,
+		Metrics:  metrics,
+		Warnings: append(runWarnings, mergeWarnings(warnings)...),
+	}, nil
 }
 
-// runSingleTest executes a single test case.
-func (r *Runner) runSingleTest(ctx context.Context, model, prompt string, schema json.RawMessage, test types.TestCase) types.TestResult {
+// runSingleTest executes a single trial of a test case, returning its result,
+// any provider warnings, and an error wrapping cassette.ErrMiss if a request
+// had no recording.
+func (r *Runner) runSingleTest(ctx context.Context, model string, params types.Params, prompt string, schema json.RawMessage, validator *jsonschema.Schema, test types.TestCase, trial int) (types.TestResult, []string, error) {
 	result := types.TestResult{
 		TestName: test.Name,
 		Expected: test.Expected,
 	}
 
-	completion, err := r.client.Complete(ctx, model, prompt, test.Input, schema)
+	completion, err := r.provider.Complete(ctx, provider.Request{
+		Model:        model,
+		SystemPrompt: prompt,
+		UserInput:    test.Input,
+		Schema:       schema,
+		Params:       params,
+		Trial:        trial,
+	})
+	if errors.Is(err, cassette.ErrMiss) {
+		return result, nil, fmt.Errorf("test %q: %w", test.Name, err)
+	}
 	if err != nil {
 		result.Error = err.Error()
-		return result
+		return result, nil, nil
 	}
 
 	result.Actual = completion.Response
 	result.Provider = completion.Provider
 	result.Latency = completion.Latency
+	result.GenerationTime = completion.GenerationTime
+	result.Cached = completion.Cached
 	result.TokensIn = completion.TokensIn
 	result.TokensOut = completion.TokensOut
+	result.CacheReadTokens = completion.CacheReadTokens
+	result.CacheWriteTokens = completion.CacheWriteTokens
+	result.Cost = completion.Cost
+
+	// A response that is not valid JSON is reported by the comparison
+	if validator != nil {
+		result.SchemaViolations, _ = validator.Validate(completion.Response)
+	}
 
 	// Compare expected vs actual
-	diffs, err := compare.Compare(test.Expected, completion.Response)
+	comparison, err := compare.Compare(test.Expected, completion.Response, r.compareOpts, r.judge.Func(ctx))
+	if errors.Is(err, cassette.ErrMiss) {
+		return result, nil, fmt.Errorf("test %q: %w", test.Name, err)
+	}
 	if err != nil {
 		result.Error = fmt.Sprintf("comparison error: %v", err)
-		return result
+		return result, completion.Warnings, nil
 	}
 
-	result.Diffs = diffs
-	result.Passed = len(diffs) == 0
+	result.Diffs = comparison.Diffs
+	result.Score = comparison.Score
+	result.Fields = comparison.Fields
+	result.Lists = comparison.Lists
+	result.Judgements = comparison.Judgements
+	result.Passed = len(comparison.Diffs) == 0 && len(result.SchemaViolations) == 0
 
-	return result
+	return result, completion.Warnings, nil
 }
 
-// calculateMetrics computes aggregated metrics from test results.
-func calculateMetrics(model string, results []types.TestResult, totalDuration time.Duration) types.ModelMetrics {
-	metrics := types.ModelMetrics{
-		Model:         model,
-		TotalTests:    len(results),
-		TotalDuration: totalDuration,
-	}
-
-	var latencies []time.Duration
-
-	for _, r := range results {
-		if r.Error != "" {
-			metrics.Errors++
-		} else if r.Passed {
-			metrics.Passed++
-		} else {
-			metrics.Failed++
-		}
-
-		metrics.TotalTokensIn += r.TokensIn
-		metrics.TotalTokensOut += r.TokensOut
-
-		if r.Latency > 0 {
-			latencies = append(latencies, r.Latency)
+// mergeWarnings flattens per-test warnings, dropping duplicates.
+func mergeWarnings(warnings [][]string) []string {
+	var merged []string
+	for _, ws := range warnings {
+		for _, w := range ws {
+			if !slices.Contains(merged, w) {
+				merged = append(merged, w)
+			}
 		}
 	}
-
-	if metrics.TotalTests > 0 {
-		metrics.Accuracy = float64(metrics.Passed) / float64(metrics.TotalTests) * 100
-	}
-
-	if totalDuration > 0 {
-		metrics.Throughput = float64(metrics.TotalTokensOut) / totalDuration.Seconds()
-	}
-
-	// Calculate latency percentiles
-	if len(latencies) > 0 {
-		slices.Sort(latencies)
-
-		metrics.LatencyP50 = percentile(latencies, 50)
-		metrics.LatencyP95 = percentile(latencies, 95)
-		metrics.LatencyP99 = percentile(latencies, 99)
-	}
-
-	return metrics
-}
-
-// percentile calculates the p-th percentile of a sorted slice.
-func percentile(sorted []time.Duration, p int) time.Duration {
-	if len(sorted) == 0 {
-		return 0
-	}
-	if len(sorted) == 1 {
-		return sorted[0]
-	}
-
-	idx := float64(p) / 100.0 * float64(len(sorted)-1)
-	lower := int(idx)
-	upper := lower + 1
-
-	if upper >= len(sorted) {
-		return sorted[len(sorted)-1]
-	}
-
-	weight := idx - float64(lower)
-	return time.Duration(float64(sorted[lower])*(1-weight) + float64(sorted[upper])*weight)
+	return merged
 }
@@ -0,0 +1,85 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"go.carr.sh/litmus/internal/cassette"
+	"go.carr.sh/litmus/internal/provider"
+	"go.carr.sh/litmus/internal/types"
+)
+
+// stubProvider answers each request with the response for its input, or
+// with err if there is none.
+type stubProvider struct {
+	responses map[string]string
+	err       error
+}
+
+func (s *stubProvider) Complete(ctx context.Context, req provider.Request) (*provider.CompletionResult, error) {
+	response, ok := s.responses[req.UserInput]
+	if !ok {
+		return nil, s.err
+	}
+	return &provider.CompletionResult{Response: json.RawMessage(response)}, nil
+}
+
+func TestRunReplayMisses(t *testing.T) {
+	dir := t.TempDir()
+	recorder, err := cassette.NewRecorder(dir, &stubProvider{responses: map[string]string{"one": `{"n":1}`}})
+	if err != nil {
+		t.Fatalf("NewRecorder() error = %v", err)
+	}
+	tests := []types.TestCase{
+		{Name: "one", Input: "one", Expected: json.RawMessage(`{"n":1}`)},
+		{Name: "two", Input: "two", Expected: json.RawMessage(`{"n":2}`)},
+		{Name: "three", Input: "three", Expected: json.RawMessage(`{"n":3}`)},
+	}
+	schema := json.RawMessage(`{"type":"object"}`)
+	if _, err := New(recorder, 1).Run(context.Background(), "gpt-test", types.Params{}, "", schema, tests[:1]); err != nil {
+		t.Fatalf("recording Run() error = %v", err)
+	}
+
+	player, err := cassette.NewPlayer(dir)
+	if err != nil {
+		t.Fatalf("NewPlayer() error = %v", err)
+	}
+
+	// A fully recorded run replays
+	run, err := New(player, 2).Run(context.Background(), "gpt-test", types.Params{}, "", schema, tests[:1])
+	if err != nil {
+		t.Fatalf("replaying Run() error = %v", err)
+	}
+	if !run.Results[0].Passed {
+		t.Errorf("replayed result = %+v, want a pass", run.Results[0])
+	}
+
+	// Every missing request is named, and none of the run is returned
+	run, err = New(player, 2).Run(context.Background(), "gpt-test", types.Params{}, "", schema, tests)
+	if !errors.Is(err, cassette.ErrMiss) {
+		t.Fatalf("Run() error = %v, want ErrMiss", err)
+	}
+	if run != nil {
+		t.Errorf("Run() = %+v, want no run", run)
+	}
+	msg := err.Error()
+	if strings.Contains(msg, `"one"`) || !strings.Contains(msg, `test "two"`) || !strings.Contains(msg, `test "three"`) {
+		t.Errorf("Run() error = %q, want only the tests two and three named", msg)
+	}
+}
+
+func TestRunProviderError(t *testing.T) {
+	p := &stubProvider{err: errors.New("rate limited")}
+	tests := []types.TestCase{{Name: "one", Input: "one", Expected: json.RawMessage(`{}`)}}
+
+	run, err := New(p, 1).Run(context.Background(), "gpt-test", types.Params{}, "", json.RawMessage(`{}`), tests)
+	if err != nil {
+		t.Fatalf("Run() error = %v, want provider errors reported per test", err)
+	}
+	if run.Results[0].Error == "" || run.Metrics.Errors != 1 {
+		t.Errorf("result = %+v, want an errored test", run.Results[0])
+	}
+}
@@ -0,0 +1,249 @@
+package runner
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go.carr.sh/litmus/internal/jsonschema"
+	"go.carr.sh/litmus/internal/types"
+)
+
+// utf8BOM is the byte order mark that spreadsheet programs often write at
+// the start of CSV files.
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// parseTable parses a CSV or TSV table of test cases. The first row is a
+// header naming the columns, which are mapped to test case fields by the
+// columns option. Cells mapped to fields of the expected value are coerced
+// to the types declared by the schema option. Empty cells are left out,
+// unless the schema declares the field a string, in which case they are "".
+func parseTable(data []byte, comma rune, o loadOptions) ([]types.TestCase, error) {
+	r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, utf8BOM)))
+	r.Comma = comma
+	r.LazyQuotes = comma == '\t'
+
+	header, err := r.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, csvError(err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, h := range header {
+		h = strings.TrimSpace(h)
+		if _, ok := columns[h]; ok && h != "" {
+			return nil, &ParseError{Line: 1, Err: fmt.Errorf("duplicate %q column", h)}
+		}
+		columns[h] = i
+	}
+
+	nameColumn := cmp.Or(o.columns.Name, "name")
+	inputColumn := cmp.Or(o.columns.Input, "input")
+	expectedColumn := cmp.Or(o.columns.Expected, "expected")
+
+	input, ok := columns[inputColumn]
+	if !ok {
+		return nil, &ParseError{Line: 1, Err: fmt.Errorf("no %q column", inputColumn)}
+	}
+	name, hasName := columns[nameColumn]
+	expected, hasExpected := columns[expectedColumn]
+
+	fields := o.columns.Fields
+	if len(fields) == 0 {
+		fields = make(map[string]string)
+		for h := range columns {
+			if h != "" && h != nameColumn && h != inputColumn && h != expectedColumn {
+				fields[h] = h
+			}
+		}
+	}
+	paths := slices.Sorted(maps.Keys(fields))
+	for _, path := range paths {
+		if _, ok := columns[fields[path]]; !ok {
+			return nil, &ParseError{Line: 1, Err: fmt.Errorf("no %q column for field %s", fields[path], path)}
+		}
+	}
+	if !hasExpected && len(paths) == 0 {
+		return nil, &ParseError{Line: 1, Err: fmt.Errorf("no %q column or columns mapped to fields", expectedColumn)}
+	}
+
+	var declared map[string][]string
+	if o.schema != nil {
+		declared, err = jsonschema.Types(o.schema)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var tests []types.TestCase
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, csvError(err)
+		}
+
+		line, _ := r.FieldPos(0)
+		test := types.TestCase{Input: record[input], Line: line}
+		if hasName {
+			test.Name = record[name]
+		} else {
+			test.Name = fmt.Sprintf("line %d", line)
+		}
+
+		var value any = map[string]any{}
+		if hasExpected && strings.TrimSpace(record[expected]) != "" {
+			if err := json.Unmarshal([]byte(record[expected]), &value); err != nil {
+				return nil, cellError(r, expected, header, err)
+			}
+		}
+		for _, path := range paths {
+			column := columns[fields[path]]
+			cell := record[column]
+			if strings.TrimSpace(cell) == "" && !slices.Contains(declared[path], "string") {
+				continue
+			}
+			v, err := coerceCell(cell, path, declared)
+			if err != nil {
+				return nil, cellError(r, column, header, err)
+			}
+			if err := setPath(value, path, v); err != nil {
+				return nil, cellError(r, column, header, err)
+			}
+		}
+
+		test.Expected, err = json.Marshal(value)
+		if err != nil {
+			return nil, &ParseError{Line: line, Err: err}
+		}
+		tests = append(tests, test)
+	}
+
+	return tests, nil
+}
+
+// coerceCell converts a cell to the first of the types declared for path
+// that it can be read as. Numbers and booleans, including "yes" and "no",
+// are parsed, objects are parsed as JSON, and arrays are parsed as JSON or
+// as a comma-separated list of items. "null" is null where the type allows
+// it. Cells of fields without a declared type are strings.
+func coerceCell(cell, path string, declared map[string][]string) (any, error) {
+	allowed := declared[path]
+	s := strings.TrimSpace(cell)
+	if s == "null" && slices.Contains(allowed, "null") {
+		return nil, nil
+	}
+
+	var tried []string
+	for _, t := range allowed {
+		switch t {
+		case "string":
+			return cell, nil
+		case "number", "integer":
+			n, err := strconv.ParseFloat(s, 64)
+			if err == nil && (t == "number" || n == math.Trunc(n)) {
+				return n, nil
+			}
+		case "boolean":
+			switch strings.ToLower(s) {
+			case "yes", "y":
+				return true, nil
+			case "no", "n":
+				return false, nil
+			}
+			if b, err := strconv.ParseBool(s); err == nil {
+				return b, nil
+			}
+		case "object":
+			var v map[string]any
+			if err := json.Unmarshal([]byte(s), &v); err == nil {
+				return v, nil
+			}
+		case "array":
+			if v, err := coerceList(s, path, declared); err == nil {
+				return v, nil
+			}
+		default:
+			continue
+		}
+		tried = append(tried, t)
+	}
+
+	if len(tried) == 0 {
+		return cell, nil
+	}
+	return nil, fmt.Errorf("%s: %q is not %s", path, cell, strings.Join(tried, " or "))
+}
+
+// coerceList converts a cell holding a JSON array or a comma-separated list
+// of items to an array, coercing each item to the declared item types.
+func coerceList(s, path string, declared map[string][]string) ([]any, error) {
+	var items []any
+	if strings.HasPrefix(s, "[") {
+		if err := json.Unmarshal([]byte(s), &items); err != nil {
+			return nil, err
+		}
+		return items, nil
+	}
+
+	for _, item := range strings.Split(s, ",") {
+		v, err := coerceCell(strings.TrimSpace(item), path+"[*]", declared)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, v)
+	}
+	return items, nil
+}
+
+// setPath sets the field at a dotted path in an expected value, creating
+// objects along the way.
+func setPath(value any, path string, v any) error {
+	obj, ok := value.(map[string]any)
+	if !ok {
+		return errors.New("expected value is not an object")
+	}
+
+	parts := strings.Split(path, ".")
+	for i, part := range parts[:len(parts)-1] {
+		child, ok := obj[part]
+		if !ok {
+			child = map[string]any{}
+			obj[part] = child
+		}
+		if obj, ok = child.(map[string]any); !ok {
+			return fmt.Errorf("%s is not an object", strings.Join(parts[:i+1], "."))
+		}
+	}
+	obj[parts[len(parts)-1]] = v
+	return nil
+}
+
+// cellError locates an error in a cell of the current row.
+func cellError(r *csv.Reader, column int, header []string, err error) error {
+	line, _ := r.FieldPos(column)
+	return &ParseError{Line: line, Err: fmt.Errorf("column %q: %w", strings.TrimSpace(header[column]), err)}
+}
+
+// csvError locates a CSV syntax error.
+func csvError(err error) error {
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return &ParseError{Line: parseErr.Line, Err: parseErr.Err}
+	}
+	return &ParseError{Err: err}
+}
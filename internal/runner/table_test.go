@@ -0,0 +1,265 @@
+package runner
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"go.carr.sh/litmus/internal/types"
+)
+
+// tableSchema declares a type for each kind of cell coercion.
+const tableSchema = `{
+  "type": "object",
+  "properties": {
+    "label": {"type": "string"},
+    "note": {"type": ["string", "null"]},
+    "count": {"type": "integer"},
+    "score": {"type": "number"},
+    "ok": {"type": "boolean"},
+    "tags": {"type": "array", "items": {"type": "string"}},
+    "ids": {"type": "array", "items": {"type": "integer"}},
+    "meta": {"type": "object"},
+    "rank": {"type": ["integer", "null"]},
+    "address": {"type": "object", "properties": {"city": {"type": "string"}}}
+  }
+}`
+
+func TestParseTable(t *testing.T) {
+	// want is the name, input, line and expected value of each test case.
+	type want struct {
+		name     string
+		input    string
+		line     int
+		expected string
+	}
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		opts    []LoadOption
+		want    []want
+	}{
+		{
+			name:    "expected column",
+			file:    "tests.csv",
+			content: "name,input,expected\none,first,\"{\"\"n\"\": 1}\"\ntwo,second,\n",
+			want:    []want{{"one", "first", 2, `{"n":1}`}, {"two", "second", 3, `{}`}},
+		},
+		{
+			name:    "field columns",
+			file:    "tests.csv",
+			content: "name,input,label,address.city\none,first,a,Paris\n",
+			want:    []want{{"one", "first", 2, `{"address":{"city":"Paris"},"label":"a"}`}},
+		},
+		{
+			name:    "fields added to the expected column",
+			file:    "tests.csv",
+			content: "name,input,expected,label\none,first,\"{\"\"n\"\": 1}\",a\n",
+			want:    []want{{"one", "first", 2, `{"label":"a","n":1}`}},
+		},
+		{
+			name:    "renamed columns",
+			file:    "tests.csv",
+			content: "Case,Text,City,Ignored\none,first,Paris,x\n",
+			opts: []LoadOption{WithColumns(types.Columns{
+				Name:   "Case",
+				Input:  "Text",
+				Fields: map[string]string{"address.city": "City"},
+			})},
+			want: []want{{"one", "first", 2, `{"address":{"city":"Paris"}}`}},
+		},
+		{
+			name:    "no name column",
+			file:    "tests.csv",
+			content: "input,label\nfirst,a\nsecond,b\n",
+			want:    []want{{"line 2", "first", 2, `{"label":"a"}`}, {"line 3", "second", 3, `{"label":"b"}`}},
+		},
+		{
+			name:    "multi-line cells",
+			file:    "tests.csv",
+			content: "name,input,label\none,\"first\nline\",a\ntwo,second,b\n",
+			want:    []want{{"one", "first\nline", 2, `{"label":"a"}`}, {"two", "second", 4, `{"label":"b"}`}},
+		},
+		{
+			name:    "byte order mark and trimmed headers",
+			file:    "tests.csv",
+			content: "\xef\xbb\xbfname, input ,label\none,first,a\n",
+			want:    []want{{"one", "first", 2, `{"label":"a"}`}},
+		},
+		{
+			name:    "tsv",
+			file:    "tests.tsv",
+			content: "name\tinput\tlabel\none\tsays \"hi\"\ta\n",
+			want:    []want{{"one", `says "hi"`, 2, `{"label":"a"}`}},
+		},
+		{
+			name:    "empty cells without a schema",
+			file:    "tests.csv",
+			content: "name,input,label,count\none,first,,\n",
+			want:    []want{{"one", "first", 2, `{}`}},
+		},
+		{
+			name:    "header only",
+			file:    "tests.csv",
+			content: "name,input,label\n",
+		},
+		{name: "empty file", file: "tests.csv", content: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := loadString(t, tt.file, tt.content, tt.opts...)
+			if err != nil {
+				t.Fatalf("LoadTestFile() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("LoadTestFile() = %d test cases, want %d", len(got), len(tt.want))
+			}
+			for i, w := range tt.want {
+				g := got[i]
+				if g.Name != w.name || g.Input != w.input || g.Line != w.line || string(g.Expected) != w.expected {
+					t.Errorf("test %d = %q with input %q on line %d expecting %s, want %q with input %q on line %d expecting %s",
+						i, g.Name, g.Input, g.Line, g.Expected, w.name, w.input, w.line, w.expected)
+				}
+			}
+		})
+	}
+}
+
+func TestParseTableCoercion(t *testing.T) {
+	tests := []struct {
+		name   string
+		column string
+		cell   string
+		// want is the expected value of the test case.
+		want string
+	}{
+		{"string", "label", "007", `{"label":"007"}`},
+		{"string keeps spaces", "label", " a ", `{"label":" a "}`},
+		{"empty string", "label", "", `{"label":""}`},
+		{"empty nullable string", "note", "", `{"note":""}`},
+		{"null", "note", "null", `{"note":null}`},
+		{"null string", "label", "null", `{"label":"null"}`},
+		{"integer", "count", "42", `{"count":42}`},
+		{"empty integer", "count", "", `{}`},
+		{"blank integer", "count", "  ", `{}`},
+		{"number", "score", "0.25", `{"score":0.25}`},
+		{"boolean", "ok", "true", `{"ok":true}`},
+		{"yes", "ok", "Yes", `{"ok":true}`},
+		{"no", "ok", "n", `{"ok":false}`},
+		{"list", "tags", "a, b", `{"tags":["a","b"]}`},
+		{"integer list", "ids", "1,2", `{"ids":[1,2]}`},
+		{"json array", "tags", `["a,b"]`, `{"tags":["a,b"]}`},
+		{"object", "meta", `{"k": 1}`, `{"meta":{"k":1}}`},
+		{"nullable integer", "rank", "null", `{"rank":null}`},
+		{"empty nullable integer", "rank", "", `{}`},
+		{"nested string", "address.city", "", `{"address":{"city":""}}`},
+		{"undeclared field", "other", "1", `{"other":"1"}`},
+		{"empty undeclared field", "other", "", `{}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := "name,input,\"" + tt.column + "\"\none,first,\"" + strings.ReplaceAll(tt.cell, `"`, `""`) + "\"\n"
+			got, err := loadString(t, "tests.csv", content, WithSchema(json.RawMessage(tableSchema)))
+			if err != nil {
+				t.Fatalf("LoadTestFile() error = %v", err)
+			}
+			if string(got[0].Expected) != tt.want {
+				t.Errorf("Expected = %s, want %s", got[0].Expected, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTableErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		opts     []LoadOption
+		wantLine int
+		wantErr  string
+	}{
+		{
+			name:     "duplicate columns",
+			content:  "name,input,label,label\none,first,a,b\n",
+			wantLine: 1,
+			wantErr:  `duplicate "label" column`,
+		},
+		{
+			name:     "duplicate columns after trimming",
+			content:  "name,input,label, label\none,first,a,b\n",
+			wantLine: 1,
+			wantErr:  `duplicate "label" column`,
+		},
+		{
+			name:     "no input column",
+			content:  "name,text,label\none,first,a\n",
+			wantLine: 1,
+			wantErr:  `no "input" column`,
+		},
+		{
+			name:     "no column for a field",
+			content:  "name,input,City\none,first,Paris\n",
+			opts:     []LoadOption{WithColumns(types.Columns{Fields: map[string]string{"address.city": "Town"}})},
+			wantLine: 1,
+			wantErr:  `no "Town" column for field address.city`,
+		},
+		{
+			name:     "no expected values",
+			content:  "name,input\none,first\n",
+			wantLine: 1,
+			wantErr:  `no "expected" column or columns mapped to fields`,
+		},
+		{
+			name:     "invalid expected json",
+			content:  "name,input,expected\none,first,{}\ntwo,second,{\n",
+			wantLine: 3,
+			wantErr:  `column "expected"`,
+		},
+		{
+			name:     "cell of the wrong type",
+			content:  "name,input,count\none,first,1\ntwo,second,many\n",
+			opts:     []LoadOption{WithSchema(json.RawMessage(tableSchema))},
+			wantLine: 3,
+			wantErr:  `column "count": count: "many" is not integer`,
+		},
+		{
+			name:     "fractional integer",
+			content:  "name,input,count\none,first,1.5\n",
+			opts:     []LoadOption{WithSchema(json.RawMessage(tableSchema))},
+			wantLine: 2,
+			wantErr:  `"1.5" is not integer`,
+		},
+		{
+			name:     "field inside a non-object",
+			content:  "name,input,expected,label.x\none,first,\"{\"\"label\"\": 1}\",a\n",
+			wantLine: 2,
+			wantErr:  "label is not an object",
+		},
+		{
+			name:     "unclosed quote",
+			content:  "name,input,label\none,\"first,a\n",
+			wantLine: 2,
+			wantErr:  "extraneous or missing \" in quoted-field",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadString(t, "tests.csv", tt.content, tt.opts...)
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("LoadTestFile() error = %v, want a *ParseError", err)
+			}
+			if parseErr.Line != tt.wantLine {
+				t.Errorf("Line = %d, want %d (%v)", parseErr.Line, tt.wantLine, err)
+			}
+			if !strings.Contains(parseErr.Err.Error(), tt.wantErr) {
+				t.Errorf("Err = %q, want it to contain %q", parseErr.Err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,307 @@
+package runner
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"go.carr.sh/litmus/internal/types"
+	"go.carr.sh/litmus/internal/util"
+)
+
+// Test file formats, detected from the file extension by Format.
+const (
+	// FormatJSON is a JSON array of test cases.
+	FormatJSON = "json"
+	// FormatJSONL is one JSON test case per line.
+	FormatJSONL = "jsonl"
+	// FormatYAML is a YAML list of test cases.
+	FormatYAML = "yaml"
+	// FormatCSV is a table of test cases with a header row.
+	FormatCSV = "csv"
+	// FormatTSV is a tab-separated table of test cases with a header row.
+	FormatTSV = "tsv"
+)
+
+// Format returns the format of a test file from its extension. Files with
+// an unknown extension are read as JSON.
+func Format(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonl", ".ndjson":
+		return FormatJSONL
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".csv":
+		return FormatCSV
+	case ".tsv":
+		return FormatTSV
+	default:
+		return FormatJSON
+	}
+}
+
+// ParseError is an error in a test or schema file, located by line.
+type ParseError struct {
+	// Line is the 1-based line of the error, or 0 if it is not known.
+	Line int
+	// Err is the underlying error.
+	Err error
+}
+
+// Error returns the error prefixed with its line.
+func (e *ParseError) Error() string {
+	if e.Line == 0 {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// LoadOption configures how a test file is loaded.
+type LoadOption func(*loadOptions)
+
+// loadOptions are the options for loading a test file.
+type loadOptions struct {
+	// columns map the columns of CSV and TSV files to test case fields.
+	columns types.Columns
+	// schema is used to coerce CSV and TSV cells to the declared types.
+	schema json.RawMessage
+}
+
+// WithColumns sets how the columns of CSV and TSV files map to test case
+// fields.
+func WithColumns(c types.Columns) LoadOption {
+	return func(o *loadOptions) {
+		o.columns = c
+	}
+}
+
+// WithSchema sets the schema whose declared types CSV and TSV cells are
+// coerced to. Without a schema, cells mapped to fields are strings.
+func WithSchema(schema json.RawMessage) LoadOption {
+	return func(o *loadOptions) {
+		o.schema = schema
+	}
+}
+
+// LoadTestFile loads test cases from a JSON, JSON Lines, YAML, CSV or TSV
+// file, chosen by Format. Parse errors are returned as a *ParseError giving
+// the line.
+func LoadTestFile(path string, opts ...LoadOption) ([]types.TestCase, error) {
+	var o loadOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read test file: %w", err)
+	}
+
+	var tests []types.TestCase
+	switch Format(path) {
+	case FormatJSONL:
+		tests, err = parseJSONL(data)
+	case FormatYAML:
+		tests, err = parseYAML(data)
+	case FormatCSV:
+		tests, err = parseTable(data, ',', o)
+	case FormatTSV:
+		tests, err = parseTable(data, '\t', o)
+	default:
+		tests, err = parseJSON(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse test file: %w", err)
+	}
+
+	return tests, nil
+}
+
+// parseJSON parses a JSON array of test cases.
+func parseJSON(data []byte) ([]types.TestCase, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil {
+		return nil, jsonError(data, 0, err)
+	} else if tok != json.Delim('[') {
+		return nil, &ParseError{Line: 1, Err: errors.New("expected an array of test cases")}
+	}
+
+	var tests []types.TestCase
+	for dec.More() {
+		start := util.ValueStart(data, int(dec.InputOffset()))
+		var test types.TestCase
+		if err := dec.Decode(&test); err != nil {
+			return nil, jsonError(data, start, err)
+		}
+		test.Line = util.LineAt(data, start)
+		tests = append(tests, test)
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, jsonError(data, 0, err)
+	}
+
+	return tests, nil
+}
+
+// parseJSONL parses one JSON test case per line, skipping blank lines.
+func parseJSONL(data []byte) ([]types.TestCase, error) {
+	var tests []types.TestCase
+	for i, line := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		var test types.TestCase
+		if err := json.Unmarshal(line, &test); err != nil {
+			return nil, &ParseError{Line: i + 1, Err: err}
+		}
+		test.Line = i + 1
+		tests = append(tests, test)
+	}
+
+	return tests, nil
+}
+
+// jsonError locates a JSON decoding error. Syntax errors are located by
+// their offset in data, and type errors by their offset from start, the
+// offset of the value being decoded.
+func jsonError(data []byte, start int, err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		return &ParseError{Line: util.LineAt(data, int(syntaxErr.Offset)), Err: err}
+	case errors.As(err, &typeErr):
+		return &ParseError{Line: util.LineAt(data, start+int(typeErr.Offset)), Err: err}
+	default:
+		return &ParseError{Err: err}
+	}
+}
+
+// parseYAML parses a YAML list of test cases. Multi-line inputs can be
+// written as block scalars, e.g. "input: |".
+func parseYAML(data []byte) ([]types.TestCase, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, yamlError(err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.SequenceNode {
+		return nil, &ParseError{Line: root.Line, Err: errors.New("expected a list of test cases")}
+	}
+
+	tests := make([]types.TestCase, 0, len(root.Content))
+	for _, item := range root.Content {
+		if item.Kind != yaml.MappingNode {
+			return nil, &ParseError{Line: item.Line, Err: errors.New("expected a test case mapping")}
+		}
+
+		test := types.TestCase{Line: item.Line}
+		for i := 0; i+1 < len(item.Content); i += 2 {
+			key, value := item.Content[i], item.Content[i+1]
+			var err error
+			switch key.Value {
+			case "name":
+				err = value.Decode(&test.Name)
+			case "input":
+				err = value.Decode(&test.Input)
+			case "expected":
+				var v any
+				if v, err = yamlValue(value); err == nil {
+					test.Expected, err = json.Marshal(v)
+				}
+			}
+			if err != nil {
+				return nil, yamlError(err)
+			}
+		}
+		tests = append(tests, test)
+	}
+
+	return tests, nil
+}
+
+// yamlValue converts a YAML node to the value it would have in JSON.
+// Timestamps are kept as strings, and mapping keys must be scalars.
+func yamlValue(node *yaml.Node) (any, error) {
+	switch node.Kind {
+	case yaml.AliasNode:
+		return yamlValue(node.Alias)
+	case yaml.SequenceNode:
+		values := make([]any, 0, len(node.Content))
+		for _, child := range node.Content {
+			v, err := yamlValue(child)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		}
+		return values, nil
+	case yaml.MappingNode:
+		values := make(map[string]any, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			if key.Kind != yaml.ScalarNode {
+				return nil, &ParseError{Line: key.Line, Err: errors.New("mapping keys must be strings")}
+			}
+			v, err := yamlValue(node.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			values[key.Value] = v
+		}
+		return values, nil
+	case yaml.ScalarNode:
+		if node.ShortTag() == "!!timestamp" {
+			return node.Value, nil
+		}
+		var v any
+		if err := node.Decode(&v); err != nil {
+			return nil, err
+		}
+		return v, nil
+	default:
+		return nil, &ParseError{Line: node.Line, Err: errors.New("unsupported YAML node")}
+	}
+}
+
+// yamlLine matches the line number in a YAML error message.
+var yamlLine = regexp.MustCompile(`^(?:yaml: )?line (\d+): `)
+
+// yamlError locates a YAML decoding error by the line in its message.
+func yamlError(err error) error {
+	var parseErr *ParseError
+	if errors.As(err, &parseErr) {
+		return err
+	}
+
+	msg := err.Error()
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) && len(typeErr.Errors) > 0 {
+		msg = typeErr.Errors[0]
+	}
+
+	m := yamlLine.FindStringSubmatch(msg)
+	if m == nil {
+		return &ParseError{Err: errors.New(strings.TrimPrefix(msg, "yaml: "))}
+	}
+	line, _ := strconv.Atoi(m[1])
+	return &ParseError{Line: line, Err: errors.New(msg[len(m[0]):])}
+}
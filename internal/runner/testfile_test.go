@@ -0,0 +1,245 @@
+package runner
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.carr.sh/litmus/internal/types"
+)
+
+// loadString writes content to a test file named name and loads it.
+func loadString(t *testing.T, name, content string, opts ...LoadOption) ([]types.TestCase, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return LoadTestFile(path, opts...)
+}
+
+func TestLoadTestFile(t *testing.T) {
+	// want is the name, line and expected value of each test case.
+	type want struct {
+		name     string
+		line     int
+		expected string
+	}
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    []want
+	}{
+		{
+			name: "json",
+			file: "tests.json",
+			content: `[
+  {"name": "one", "input": "1", "expected": {"n": 1}},
+
+  {
+    "name": "two", "input": "2", "expected": {"n": 2}
+  }
+]`,
+			want: []want{{"one", 2, `{"n": 1}`}, {"two", 4, `{"n": 2}`}},
+		},
+		{
+			name: "json lines",
+			file: "tests.jsonl",
+			content: `{"name": "one", "input": "1", "expected": {"n": 1}}
+
+{"name": "two", "input": "2", "expected": {"n": 2}}
+`,
+			want: []want{{"one", 1, `{"n": 1}`}, {"two", 3, `{"n": 2}`}},
+		},
+		{
+			name: "yaml",
+			file: "tests.yaml",
+			content: `- name: one
+  input: |
+    first line
+    second line
+  expected:
+    n: 1
+    tags: [a, b]
+- name: two
+  input: "2"
+  expected: {n: 2.5, ok: true, none: null}
+`,
+			want: []want{
+				{"one", 1, `{"n":1,"tags":["a","b"]}`},
+				{"two", 8, `{"n":2.5,"none":null,"ok":true}`},
+			},
+		},
+		{
+			name: "yaml timestamps as strings",
+			file: "tests.yml",
+			content: `- name: dates
+  input: x
+  expected:
+    date: 2024-01-02
+    at: 2024-01-02T15:04:05Z
+    quoted: "2024-01-02"
+`,
+			want: []want{{"dates", 1, `{"at":"2024-01-02T15:04:05Z","date":"2024-01-02","quoted":"2024-01-02"}`}},
+		},
+		{
+			name: "yaml aliases",
+			file: "tests.yaml",
+			content: `- name: one
+  input: x
+  expected: &value {n: 1}
+- name: two
+  input: y
+  expected: *value
+`,
+			want: []want{{"one", 1, `{"n":1}`}, {"two", 4, `{"n":1}`}},
+		},
+		{name: "empty yaml", file: "tests.yaml", content: ""},
+		{name: "empty json lines", file: "tests.jsonl", content: "\n\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := loadString(t, tt.file, tt.content)
+			if err != nil {
+				t.Fatalf("LoadTestFile() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("LoadTestFile() = %d test cases, want %d", len(got), len(tt.want))
+			}
+			for i, w := range tt.want {
+				if got[i].Name != w.name || got[i].Line != w.line || string(got[i].Expected) != w.expected {
+					t.Errorf("test %d = %q on line %d expecting %s, want %q on line %d expecting %s",
+						i, got[i].Name, got[i].Line, got[i].Expected, w.name, w.line, w.expected)
+				}
+			}
+		})
+	}
+
+	// Multi-line YAML inputs keep their line breaks
+	got, err := loadString(t, "tests.yaml", tests[2].content)
+	if err != nil {
+		t.Fatalf("LoadTestFile() error = %v", err)
+	}
+	if got[0].Input != "first line\nsecond line\n" {
+		t.Errorf("Input = %q, want the block scalar", got[0].Input)
+	}
+}
+
+func TestLoadTestFileErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		wantLine int
+		wantErr  string
+	}{
+		{
+			name:     "json syntax error",
+			file:     "tests.json",
+			content:  "[\n  {\"name\": \"one\",\n   \"input\": }\n]",
+			wantLine: 3,
+			wantErr:  "invalid character '}'",
+		},
+		{
+			name:     "json type error",
+			file:     "tests.json",
+			content:  "[\n  {\"name\": \"one\", \"input\": \"1\"},\n  {\n    \"name\": 2,\n    \"input\": \"2\"\n  }\n]",
+			wantLine: 4,
+			wantErr:  "cannot unmarshal number",
+		},
+		{
+			name:     "json object",
+			file:     "tests.json",
+			content:  `{"name": "one"}`,
+			wantLine: 1,
+			wantErr:  "expected an array of test cases",
+		},
+		{
+			name:     "json lines syntax error",
+			file:     "tests.jsonl",
+			content:  "{\"name\": \"one\", \"input\": \"1\"}\n\n{\"name\": \"two\",\n",
+			wantLine: 3,
+			wantErr:  "unexpected end of JSON input",
+		},
+		{
+			name:     "json lines type error",
+			file:     "tests.ndjson",
+			content:  "{\"name\": \"one\", \"input\": \"1\"}\n{\"name\": \"two\", \"input\": [2]}\n",
+			wantLine: 2,
+			wantErr:  "cannot unmarshal array",
+		},
+		{
+			name:     "yaml syntax error",
+			file:     "tests.yaml",
+			content:  "- name: one\n  input: x\n- name: two\n  input: \"y\n",
+			wantLine: 4,
+			wantErr:  "found unexpected end of stream",
+		},
+		{
+			// The parser reports the line before the unclosed mapping
+			name:     "yaml unclosed flow mapping",
+			file:     "tests.yaml",
+			content:  "- name: one\n  input: x\n  expected: {n: 1\n",
+			wantLine: 2,
+			wantErr:  "did not find expected ',' or '}'",
+		},
+		{
+			name:     "yaml type error",
+			file:     "tests.yaml",
+			content:  "- name: one\n  input: x\n- name: two\n  input: [a, b]\n",
+			wantLine: 4,
+			wantErr:  "cannot unmarshal !!seq into string",
+		},
+		{
+			name:     "yaml mapping",
+			file:     "tests.yaml",
+			content:  "name: one\ninput: x\n",
+			wantLine: 1,
+			wantErr:  "expected a list of test cases",
+		},
+		{
+			name:     "yaml test case not a mapping",
+			file:     "tests.yaml",
+			content:  "- name: one\n  input: x\n- two\n",
+			wantLine: 3,
+			wantErr:  "expected a test case mapping",
+		},
+		{
+			name:     "yaml non-scalar key",
+			file:     "tests.yaml",
+			content:  "- name: one\n  input: x\n  expected:\n    n: 1\n    ? [a, b]\n    : 2\n",
+			wantLine: 5,
+			wantErr:  "mapping keys must be strings",
+		},
+		{
+			name:     "yaml nested non-scalar key",
+			file:     "tests.yaml",
+			content:  "- name: one\n  input: x\n  expected:\n    items:\n      - ? {a: 1}\n        : 2\n",
+			wantLine: 5,
+			wantErr:  "mapping keys must be strings",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadString(t, tt.file, tt.content)
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("LoadTestFile() error = %v, want a *ParseError", err)
+			}
+			if parseErr.Line != tt.wantLine {
+				t.Errorf("Line = %d, want %d (%v)", parseErr.Line, tt.wantLine, err)
+			}
+			if !strings.Contains(parseErr.Err.Error(), tt.wantErr) {
+				t.Errorf("Err = %q, want it to contain %q", parseErr.Err, tt.wantErr)
+			}
+			if strings.Contains(parseErr.Err.Error(), "line ") {
+				t.Errorf("Err = %q repeats the line", parseErr.Err)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package runner
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+
+	"go.carr.sh/litmus/internal/compare"
+	"go.carr.sh/litmus/internal/types"
+)
+
+// aggregateTrials combines the results of repeated runs of a test into a
+// single result. The diffs are taken from the first trial that ran and did
+// not pass, so the report shows a representative failure. Errored trials
+// count as failed attempts, and the test is only reported as errored if
+// every trial errored.
+func aggregateTrials(trials []types.TestResult) types.TestResult {
+	if len(trials) == 1 {
+		return trials[0]
+	}
+
+	idx := slices.IndexFunc(trials, func(t types.TestResult) bool { return !t.Passed && t.Error == "" })
+	if idx < 0 {
+		idx = slices.IndexFunc(trials, func(t types.TestResult) bool { return !t.Passed })
+	}
+	result := trials[max(idx, 0)]
+	result.Trials = trials
+	result.Passed = idx < 0
+	if slices.ContainsFunc(trials, func(t types.TestResult) bool { return t.Error == "" }) {
+		result.Error = ""
+	}
+	result.Cached = true
+	result.TokensIn, result.TokensOut = 0, 0
+	result.CacheReadTokens, result.CacheWriteTokens = 0, 0
+	result.GenerationTime = 0
+	result.Cost = nil
+	result.Score = 0
+
+	var passed int
+	var latencies []time.Duration
+	for _, t := range trials {
+		if t.Passed {
+			passed++
+		}
+		result.Cached = result.Cached && t.Cached
+		result.TokensIn += t.TokensIn
+		result.TokensOut += t.TokensOut
+		result.CacheReadTokens += t.CacheReadTokens
+		result.CacheWriteTokens += t.CacheWriteTokens
+		result.GenerationTime += t.GenerationTime
+		if t.Cost != nil {
+			if result.Cost == nil {
+				result.Cost = new(float64)
+			}
+			*result.Cost += *t.Cost
+		}
+		result.Score += t.Score / float64(len(trials))
+		if t.Latency > 0 {
+			latencies = append(latencies, t.Latency)
+		}
+	}
+
+	slices.Sort(latencies)
+	result.Latency = percentile(latencies, 50)
+	result.PassRate = float64(passed) / float64(len(trials))
+	result.Agreement = agreement(trials)
+
+	return result
+}
+
+// agreement returns the mean, over every output field seen in any trial, of
+// the share of trials that agree with the most common value of that field.
+// A field missing from a trial counts as a distinct value. Trials without a
+// parseable response are ignored, and 0 is returned if fewer than two remain.
+func agreement(trials []types.TestResult) float64 {
+	var outputs []map[string]any
+	for _, t := range trials {
+		if t.Error != "" {
+			continue
+		}
+		var v any
+		if err := json.Unmarshal(t.Actual, &v); err != nil {
+			continue
+		}
+		outputs = append(outputs, compare.Flatten(v))
+	}
+	if len(outputs) < 2 {
+		return 0
+	}
+
+	paths := make(map[string]bool)
+	for _, leaves := range outputs {
+		for path := range leaves {
+			paths[path] = true
+		}
+	}
+
+	var total float64
+	for path := range paths {
+		counts := make(map[string]int)
+		for _, leaves := range outputs {
+			key := "\x00absent"
+			if v, ok := leaves[path]; ok {
+				b, _ := json.Marshal(v)
+				key = string(b)
+			}
+			counts[key]++
+		}
+		mode := 0
+		for _, n := range counts {
+			mode = max(mode, n)
+		}
+		total += float64(mode) / float64(len(outputs))
+	}
+
+	return total / float64(len(paths))
+}
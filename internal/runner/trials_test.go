@@ -0,0 +1,78 @@
+package runner
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"go.carr.sh/litmus/internal/types"
+)
+
+func TestAggregateTrials(t *testing.T) {
+	pass := types.TestResult{TestName: "t", Passed: true, Score: 1, Actual: json.RawMessage(`{"a":1}`)}
+	fail := types.TestResult{TestName: "t", Score: 0.5, Actual: json.RawMessage(`{"a":2}`), Diffs: []types.FieldDiff{{Path: "a"}}}
+	errored := types.TestResult{TestName: "t", Error: "request failed: timeout"}
+
+	tests := []struct {
+		name     string
+		trials   []types.TestResult
+		passed   bool
+		passRate float64
+		score    float64
+		// wantError is true if the test should be reported as errored.
+		wantError bool
+		// wantDiffs is true if the representative trial has diffs.
+		wantDiffs bool
+	}{
+		{name: "all passed", trials: []types.TestResult{pass, pass, pass}, passed: true, passRate: 1, score: 1},
+		{name: "flaky", trials: []types.TestResult{pass, fail, pass}, passRate: 2.0 / 3, score: 2.5 / 3, wantDiffs: true},
+		{name: "errored trial counts as a failed attempt", trials: []types.TestResult{pass, errored, pass}, passRate: 2.0 / 3, score: 2.0 / 3},
+		{name: "failure preferred over error", trials: []types.TestResult{errored, fail, pass}, passRate: 1.0 / 3, score: 1.5 / 3, wantDiffs: true},
+		{name: "errors and failures", trials: []types.TestResult{errored, fail}, passRate: 0, score: 0.25, wantDiffs: true},
+		{name: "every trial errored", trials: []types.TestResult{errored, errored}, passRate: 0, score: 0, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := aggregateTrials(tt.trials)
+			if r.Passed != tt.passed {
+				t.Errorf("Passed = %v, want %v", r.Passed, tt.passed)
+			}
+			if math.Abs(r.PassRate-tt.passRate) > 1e-9 {
+				t.Errorf("PassRate = %v, want %v", r.PassRate, tt.passRate)
+			}
+			if math.Abs(r.Score-tt.score) > 1e-9 {
+				t.Errorf("Score = %v, want %v", r.Score, tt.score)
+			}
+			if (r.Error != "") != tt.wantError {
+				t.Errorf("Error = %q, want error %v", r.Error, tt.wantError)
+			}
+			if (len(r.Diffs) > 0) != tt.wantDiffs {
+				t.Errorf("Diffs = %v, want diffs %v", r.Diffs, tt.wantDiffs)
+			}
+			if len(r.Trials) != len(tt.trials) {
+				t.Errorf("len(Trials) = %d, want %d", len(r.Trials), len(tt.trials))
+			}
+		})
+	}
+}
+
+func TestMetricsWithErroredTrials(t *testing.T) {
+	pass := types.TestResult{Passed: true, Score: 1}
+	errored := types.TestResult{Error: "request failed: timeout"}
+	results := []types.TestResult{
+		aggregateTrials([]types.TestResult{pass, errored, pass}),
+		aggregateTrials([]types.TestResult{errored, errored, errored}),
+	}
+
+	m := calculateMetrics("gpt-test", results, 0)
+	if m.Failed != 1 || m.Errors != 1 || m.Passed != 0 {
+		t.Errorf("passed %d, failed %d, errors %d, want 0, 1, 1", m.Passed, m.Failed, m.Errors)
+	}
+	if m.PassAtK != 50 || m.PassHatK != 0 {
+		t.Errorf("pass@k = %v, pass^k = %v, want 50, 0", m.PassAtK, m.PassHatK)
+	}
+	if math.Abs(m.PassRate-100.0/3) > 1e-9 {
+		t.Errorf("PassRate = %v, want %v", m.PassRate, 100.0/3)
+	}
+}
@@ -3,6 +3,8 @@ package types
 
 import (
 	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
 )
 
@@ -14,16 +16,207 @@ type TestCase struct {
 	Input string `json:"input"`
 	// Expected output of the test case.
 	Expected json.RawMessage `json:"expected"`
+	// Line is the line of the test file on which the test case starts, or 0
+	// if it is not known.
+	Line int `json:"-"`
 }
 
+// Columns maps the columns of a CSV or TSV test file, by header, to the
+// fields of test cases.
+type Columns struct {
+	// Name is the column holding test names, "name" by default. Rows are
+	// named by their line number if the column is absent.
+	Name string `json:"name,omitempty"`
+	// Input is the column holding inputs, "input" by default.
+	Input string `json:"input,omitempty"`
+	// Expected is the column holding expected values as JSON, "expected" by
+	// default.
+	Expected string `json:"expected,omitempty"`
+	// Fields maps dotted paths in expected values to the columns holding
+	// them, e.g. {"address.city": "City"}. If empty, every column other than
+	// the name, input and expected columns is mapped to the path in its
+	// header.
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+// Kinds of field difference, for FieldDiff.Kind.
+const (
+	// DiffChanged is a field whose value differs from the expected value.
+	DiffChanged = "changed"
+	// DiffMissing is an expected field that is absent from the response.
+	DiffMissing = "missing"
+	// DiffUnexpected is a field in the response that was not expected.
+	DiffUnexpected = "unexpected"
+	// DiffTypeMismatch is a field whose value has a different JSON type from
+	// the expected value, including null where a value was expected.
+	DiffTypeMismatch = "type_mismatch"
+)
+
 // FieldDiff represents a difference found in a specific field.
 type FieldDiff struct {
 	// Path to the field that differs.
 	Path string `json:"path"`
+	// Kind is the kind of difference: "changed", "missing", "unexpected" or
+	// "type_mismatch". Expected is nil for an unexpected field and Actual is
+	// nil for a missing one, while an explicit null is a type mismatch.
+	Kind string `json:"kind"`
 	// Expected value of the field.
 	Expected any `json:"expected"`
 	// Actual value of the field.
 	Actual any `json:"actual"`
+	// Delta is the actual minus the expected value, if both are numbers.
+	Delta *float64 `json:"delta,omitempty"`
+	// Similarity is the similarity of the strings from 0 to 1, if a
+	// similarity metric is configured for the field.
+	Similarity *float64 `json:"similarity,omitempty"`
+	// Message explains why the field differs when a matcher failed.
+	Message string `json:"message,omitempty"`
+	// JudgeScore is the score from 0 to 1 given by the judge model, if the
+	// field was judged.
+	JudgeScore *float64 `json:"judge_score,omitempty"`
+	// Rationale is the judge model's explanation of its score.
+	Rationale string `json:"rationale,omitempty"`
+}
+
+// Judgement is a judge model's verdict on a field, whether or not the field
+// matched.
+type Judgement struct {
+	// Path to the judged field.
+	Path string `json:"path"`
+	// Score is the score from 0 to 1 given by the judge model.
+	Score float64 `json:"score"`
+	// Rationale is the judge model's explanation of its score.
+	Rationale string `json:"rationale,omitempty"`
+	// Passed is true if the score reached the threshold.
+	Passed bool `json:"passed"`
+	// Cost is the cost of the judge request in USD, or nil if the provider
+	// did not report it. Verdicts reused within a run cost nothing.
+	Cost *float64 `json:"cost,omitempty"`
+}
+
+// SchemaViolation is a way in which a response does not conform to the
+// JSON schema.
+type SchemaViolation struct {
+	// Path is the path of the offending value, in the syntax of field diffs.
+	Path string `json:"path"`
+	// Keyword is the schema keyword that was violated, e.g. "minimum".
+	Keyword string `json:"keyword"`
+	// Message describes the violation.
+	Message string `json:"message"`
+}
+
+// FieldCount counts how many times a field matched.
+type FieldCount struct {
+	// Matched is the number of times the field matched.
+	Matched int `json:"matched"`
+	// Total is the number of times the field was compared.
+	Total int `json:"total"`
+}
+
+// FieldAccuracy is how often a field matched across a model's test cases.
+type FieldAccuracy struct {
+	// Path is the path of the field, with array indices normalized to [*].
+	Path string `json:"path"`
+	// FieldCount is the number of matched and total comparisons.
+	FieldCount
+	// Accuracy is the percentage of comparisons in which the field matched.
+	Accuracy float64 `json:"accuracy"`
+}
+
+// ListCount counts the elements of an array field compared as a set or
+// multiset.
+type ListCount struct {
+	// TruePositives is the number of expected elements found in the actual array.
+	TruePositives int `json:"true_positives"`
+	// FalsePositives is the number of actual elements that were not expected.
+	FalsePositives int `json:"false_positives"`
+	// FalseNegatives is the number of expected elements that were not found.
+	FalseNegatives int `json:"false_negatives"`
+}
+
+// Add returns the sum of two counts.
+func (c ListCount) Add(o ListCount) ListCount {
+	return ListCount{
+		TruePositives:  c.TruePositives + o.TruePositives,
+		FalsePositives: c.FalsePositives + o.FalsePositives,
+		FalseNegatives: c.FalseNegatives + o.FalseNegatives,
+	}
+}
+
+// Scores returns the precision, recall and F1 of the count, from 0 to 1.
+// Two empty arrays score 1 on every measure; otherwise a measure with a zero
+// denominator scores 0.
+func (c ListCount) Scores() (precision, recall, f1 float64) {
+	if c == (ListCount{}) {
+		return 1, 1, 1
+	}
+	if n := c.TruePositives + c.FalsePositives; n > 0 {
+		precision = float64(c.TruePositives) / float64(n)
+	}
+	if n := c.TruePositives + c.FalseNegatives; n > 0 {
+		recall = float64(c.TruePositives) / float64(n)
+	}
+	if precision+recall > 0 {
+		f1 = 2 * precision * recall / (precision + recall)
+	}
+	return precision, recall, f1
+}
+
+// ListMetrics are the set-based precision, recall and F1 of array fields
+// across a model's test cases, as percentages. The micro averages pool the
+// counts of every test, while the macro averages are the mean of each
+// test's scores.
+type ListMetrics struct {
+	// Path is the path of the array field, with array indices normalized to
+	// [*]. It is empty for the metrics of all array fields combined.
+	Path string `json:"path,omitempty"`
+	// ListCount is the pooled count of elements.
+	ListCount
+	// Precision is the micro-averaged precision.
+	Precision float64 `json:"precision"`
+	// Recall is the micro-averaged recall.
+	Recall float64 `json:"recall"`
+	// F1 is the micro-averaged F1 score.
+	F1 float64 `json:"f1"`
+	// MacroPrecision is the macro-averaged precision.
+	MacroPrecision float64 `json:"macro_precision"`
+	// MacroRecall is the macro-averaged recall.
+	MacroRecall float64 `json:"macro_recall"`
+	// MacroF1 is the macro-averaged F1 score.
+	MacroF1 float64 `json:"macro_f1"`
+}
+
+// ConfusionMatrix tallies the expected against the actual values of an enum
+// field across a model's test cases.
+type ConfusionMatrix struct {
+	// Path is the path of the field, with array indices normalized to [*].
+	Path string `json:"path"`
+	// Labels are the classes: the values allowed by the schema, followed by
+	// any other values seen, with "(missing)" for a field that was absent.
+	// Strings are used as is and other values are JSON-encoded.
+	Labels []string `json:"labels"`
+	// Counts holds a row per expected label and a column per actual label.
+	Counts [][]int `json:"counts"`
+	// Classes are the precision and recall of each label.
+	Classes []ClassMetrics `json:"classes"`
+	// Kappa is Cohen's kappa, the agreement between the expected and actual
+	// values corrected for chance, from -1 to 1.
+	Kappa float64 `json:"kappa"`
+}
+
+// ClassMetrics are the precision and recall of one class of an enum field,
+// as percentages.
+type ClassMetrics struct {
+	// Label is the class.
+	Label string `json:"label"`
+	// Support is the number of times the class was expected.
+	Support int `json:"support"`
+	// Precision is the share of actual values of the class that were expected.
+	Precision float64 `json:"precision"`
+	// Recall is the share of expected values of the class that were returned.
+	Recall float64 `json:"recall"`
+	// F1 is the harmonic mean of precision and recall.
+	F1 float64 `json:"f1"`
 }
 
 // TestResult represents the result of running a single test case.
@@ -38,16 +231,58 @@ type TestResult struct {
 	Actual json.RawMessage `json:"actual,omitempty"`
 	// Diffs are the differences between the expected and actual output.
 	Diffs []FieldDiff `json:"diffs,omitempty"`
+	// Score is the weighted share of fields that matched, from 0 to 1. It
+	// is 0 if the test errored.
+	Score float64 `json:"score"`
+	// Fields counts the matched and total comparisons of each field, keyed
+	// by path with array indices normalized to [*]. It is aggregated into
+	// ModelMetrics.FieldAccuracy rather than reported per test.
+	Fields map[string]FieldCount `json:"-"`
+	// Lists counts the elements of each array field compared as a set or
+	// multiset, keyed by normalized path. Like Fields, it is aggregated into
+	// ModelMetrics.Lists.
+	Lists map[string]ListCount `json:"-"`
+	// Judgements are the verdicts of the judge model on the judged fields,
+	// including those that matched.
+	Judgements []Judgement `json:"judgements,omitempty"`
+	// SchemaViolations are the ways in which the response does not conform
+	// to the schema. A test with violations does not pass, and is counted as
+	// invalid rather than failed.
+	SchemaViolations []SchemaViolation `json:"schema_violations,omitempty"`
 	// Error is the error message if the test case failed.
 	Error string `json:"error,omitempty"`
 	// Provider is the provider of the test case.
 	Provider string `json:"provider,omitempty"`
 	// Latency is the latency of the test case.
 	Latency time.Duration `json:"latency_ns"`
+	// GenerationTime is the time the model spent generating output, if
+	// reported by the provider.
+	GenerationTime time.Duration `json:"generation_ns,omitempty"`
 	// TokensIn is the number of tokens input to the test case.
 	TokensIn int `json:"tokens_in"`
 	// TokensOut is the number of tokens output from the test case.
 	TokensOut int `json:"tokens_out"`
+	// CacheReadTokens is the number of input tokens read from the provider's prompt cache.
+	CacheReadTokens int `json:"cache_read_tokens,omitempty"`
+	// CacheWriteTokens is the number of input tokens written to the provider's prompt cache.
+	CacheWriteTokens int `json:"cache_write_tokens,omitempty"`
+	// Cached is true if the response was served from the local response
+	// cache. Cached results are excluded from latency and throughput metrics.
+	Cached bool `json:"cached,omitempty"`
+	// Cost is the cost of the request in USD, or nil if the provider did
+	// not report it.
+	Cost *float64 `json:"cost,omitempty"`
+	// Trials are the individual results when the test is repeated. The
+	// other fields then describe the test as a whole: it passes only if every
+	// trial passed, the diffs and error are those of the first trial that
+	// did not pass, tokens are summed, latency is the median and the score
+	// is the mean.
+	Trials []TestResult `json:"trials,omitempty"`
+	// PassRate is the fraction of trials that passed, from 0 to 1.
+	PassRate float64 `json:"pass_rate,omitempty"`
+	// Agreement is the mean share of trials that agree with the most common
+	// value of each output field, from 0 to 1.
+	Agreement float64 `json:"agreement,omitempty"`
 }
 
 // ModelMetrics represents aggregated metrics for a single model.
@@ -62,12 +297,58 @@ type ModelMetrics struct {
 	Failed int `json:"failed"`
 	// Errors is the number of test cases that errored.
 	Errors int `json:"errors"`
+	// Invalid is the number of test cases whose response did not conform to
+	// the schema.
+	Invalid int `json:"invalid,omitempty"`
 	// Accuracy is the accuracy of the model.
 	Accuracy float64 `json:"accuracy"`
+	// MeanFieldScore is the mean field score of the test cases, as a
+	// percentage. Unlike Accuracy, it gives partial credit for tests that
+	// got some fields right.
+	MeanFieldScore float64 `json:"mean_field_score"`
+	// FieldAccuracy is the accuracy of each field across all test cases,
+	// sorted by path. Tests that errored are not included.
+	FieldAccuracy []FieldAccuracy `json:"field_accuracy,omitempty"`
+	// Lists are the set-based precision, recall and F1 of each array field,
+	// sorted by path. Tests that errored are not included.
+	Lists []ListMetrics `json:"list_metrics,omitempty"`
+	// ListOverall combines every array field, with the macro averages taken
+	// over each array field of each test. It is nil if no arrays were
+	// compared.
+	ListOverall *ListMetrics `json:"list_overall,omitempty"`
+	// Confusion are the confusion matrices of the enum fields of the schema,
+	// sorted by path. Tests that errored are not included.
+	Confusion []ConfusionMatrix `json:"confusion_matrices,omitempty"`
+	// CacheHits is the number of responses served from the local response cache.
+	CacheHits int `json:"cache_hits,omitempty"`
+	// Trials is the number of times each test was run, if more than once.
+	Trials int `json:"trials,omitempty"`
+	// PassRate is the mean per-test pass rate across trials, as a percentage.
+	PassRate float64 `json:"pass_rate,omitempty"`
+	// PassAtK is the percentage of tests that passed in at least one trial.
+	PassAtK float64 `json:"pass_at_k,omitempty"`
+	// PassHatK is the percentage of tests that passed in every trial.
+	PassHatK float64 `json:"pass_hat_k,omitempty"`
+	// Agreement is the mean per-field agreement across trials, as a percentage.
+	Agreement float64 `json:"agreement,omitempty"`
 	// TotalTokensIn is the total number of tokens input to the test cases.
 	TotalTokensIn int `json:"total_tokens_in"`
 	// TotalTokensOut is the total number of tokens output from the test cases.
 	TotalTokensOut int `json:"total_tokens_out"`
+	// TotalCacheReadTokens is the total number of input tokens read from prompt caches.
+	TotalCacheReadTokens int `json:"total_cache_read_tokens,omitempty"`
+	// TotalCacheWriteTokens is the total number of input tokens written to prompt caches.
+	TotalCacheWriteTokens int `json:"total_cache_write_tokens,omitempty"`
+	// TotalCost is the total cost of the responses in USD, as reported by
+	// the provider, including JudgeCost. Responses served from the local
+	// cache cost nothing.
+	TotalCost float64 `json:"total_cost,omitempty"`
+	// JudgeCost is the part of TotalCost spent on judge requests.
+	JudgeCost float64 `json:"judge_cost,omitempty"`
+	// UnpricedResponses is the number of responses, including judge
+	// verdicts, whose provider did not report a cost, so that TotalCost may
+	// be too low.
+	UnpricedResponses int `json:"unpriced_responses,omitempty"`
 	// LatencyP50 is the 50th percentile latency of the test cases.
 	LatencyP50 time.Duration `json:"latency_p50_ns"`
 	// LatencyP95 is the 95th percentile latency of the test cases.
@@ -78,16 +359,29 @@ type ModelMetrics struct {
 	TotalDuration time.Duration `json:"total_duration_ns"`
 	// Throughput is the throughput of the model, in tokens per second.
 	Throughput float64 `json:"throughput_tps"`
+	// GenerationThroughput is the model's generation speed in tokens per
+	// second, based on provider-reported generation time. It is zero if the
+	// provider does not report generation time.
+	GenerationThroughput float64 `json:"generation_tps,omitempty"`
 }
 
 // ModelRun represents all results from running tests against a single model.
 type ModelRun struct {
 	// Model is the name of the model.
 	Model string `json:"model"`
+	// Params are the effective sampling parameters used for the model.
+	Params Params `json:"params,omitzero"`
 	// Results are the results of the test cases.
 	Results []TestResult `json:"results"`
 	// Metrics are the metrics of the model.
 	Metrics ModelMetrics `json:"metrics"`
+	// Warnings are non-fatal problems reported while running the model.
+	Warnings []string `json:"warnings,omitempty"`
+	// FixedSchema is the schema sent to the model after --fix-schema
+	// rewrote it for the provider's strict mode, if it was changed.
+	FixedSchema json.RawMessage `json:"fixed_schema,omitempty"`
+	// Gates are the results of the quality gates evaluated for the model.
+	Gates []GateResult `json:"gates,omitempty"`
 }
 
 // RunReport represents the complete output of a test run.
@@ -102,4 +396,209 @@ type RunReport struct {
 	TestFile string `json:"test_file"`
 	// Models are the models of the test run.
 	Models []ModelRun `json:"models"`
+	// GatesPassed is true if every quality gate of every model passed. It is
+	// nil if no gates were set.
+	GatesPassed *bool `json:"gates_passed,omitempty"`
+}
+
+// Params are sampling parameters sent with each request. Nil fields are left
+// to the provider's defaults.
+type Params struct {
+	// Temperature controls randomness; lower values are more deterministic.
+	Temperature *float64 `json:"temperature,omitempty"`
+	// TopP is the nucleus sampling probability mass.
+	TopP *float64 `json:"top_p,omitempty"`
+	// Seed requests deterministic sampling, where supported.
+	Seed *int `json:"seed,omitempty"`
+	// MaxTokens is the maximum number of tokens to generate.
+	MaxTokens *int `json:"max_tokens,omitempty"`
+	// Stop is a list of sequences that end generation.
+	Stop []string `json:"stop,omitempty"`
+	// FrequencyPenalty penalises tokens by how often they have appeared.
+	FrequencyPenalty *float64 `json:"frequency_penalty,omitempty"`
+	// PresencePenalty penalises tokens that have appeared at all.
+	PresencePenalty *float64 `json:"presence_penalty,omitempty"`
+}
+
+// IsZero reports whether no parameters are set.
+func (p Params) IsZero() bool {
+	return p.Temperature == nil && p.TopP == nil && p.Seed == nil && p.MaxTokens == nil &&
+		p.Stop == nil && p.FrequencyPenalty == nil && p.PresencePenalty == nil
+}
+
+// Merge returns a copy of p with every field that is set in o overriding it.
+func (p Params) Merge(o Params) Params {
+	if o.Temperature != nil {
+		p.Temperature = o.Temperature
+	}
+	if o.TopP != nil {
+		p.TopP = o.TopP
+	}
+	if o.Seed != nil {
+		p.Seed = o.Seed
+	}
+	if o.MaxTokens != nil {
+		p.MaxTokens = o.MaxTokens
+	}
+	if o.Stop != nil {
+		p.Stop = o.Stop
+	}
+	if o.FrequencyPenalty != nil {
+		p.FrequencyPenalty = o.FrequencyPenalty
+	}
+	if o.PresencePenalty != nil {
+		p.PresencePenalty = o.PresencePenalty
+	}
+	return p
+}
+
+// String formats the parameters that are set as "name=value" pairs, or
+// returns an empty string if none are set.
+func (p Params) String() string {
+	var parts []string
+	if p.Temperature != nil {
+		parts = append(parts, fmt.Sprintf("temperature=%g", *p.Temperature))
+	}
+	if p.TopP != nil {
+		parts = append(parts, fmt.Sprintf("top_p=%g", *p.TopP))
+	}
+	if p.Seed != nil {
+		parts = append(parts, fmt.Sprintf("seed=%d", *p.Seed))
+	}
+	if p.MaxTokens != nil {
+		parts = append(parts, fmt.Sprintf("max_tokens=%d", *p.MaxTokens))
+	}
+	if p.Stop != nil {
+		parts = append(parts, fmt.Sprintf("stop=%q", p.Stop))
+	}
+	if p.FrequencyPenalty != nil {
+		parts = append(parts, fmt.Sprintf("frequency_penalty=%g", *p.FrequencyPenalty))
+	}
+	if p.PresencePenalty != nil {
+		parts = append(parts, fmt.Sprintf("presence_penalty=%g", *p.PresencePenalty))
+	}
+	return strings.Join(parts, " ")
+}
+
+// Duration is a time.Duration that is encoded in JSON as a string such as
+// "1.5s".
+type Duration time.Duration
+
+// MarshalJSON encodes the duration as a string.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON decodes a duration string such as "2s" or "500ms".
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"2s\": %w", err)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Gates are quality thresholds that a model's metrics must meet. Nil fields
+// are not checked.
+type Gates struct {
+	// MinAccuracy is the minimum accuracy, as a percentage.
+	MinAccuracy *float64 `json:"min_accuracy,omitempty"`
+	// MaxP95Latency is the maximum 95th percentile latency.
+	MaxP95Latency *Duration `json:"max_p95_latency,omitempty"`
+	// MaxErrors is the maximum number of tests that may error.
+	MaxErrors *int `json:"max_errors,omitempty"`
+	// MaxCost is the maximum total cost in USD.
+	MaxCost *float64 `json:"max_cost,omitempty"`
+}
+
+// IsZero reports whether no gates are set.
+func (g Gates) IsZero() bool {
+	return g.MinAccuracy == nil && g.MaxP95Latency == nil && g.MaxErrors == nil && g.MaxCost == nil
+}
+
+// Merge returns a copy of g with every gate that is set in o overriding it.
+func (g Gates) Merge(o Gates) Gates {
+	if o.MinAccuracy != nil {
+		g.MinAccuracy = o.MinAccuracy
+	}
+	if o.MaxP95Latency != nil {
+		g.MaxP95Latency = o.MaxP95Latency
+	}
+	if o.MaxErrors != nil {
+		g.MaxErrors = o.MaxErrors
+	}
+	if o.MaxCost != nil {
+		g.MaxCost = o.MaxCost
+	}
+	return g
+}
+
+// Evaluate checks the gates that are set against a model's metrics.
+func (g Gates) Evaluate(m ModelMetrics) []GateResult {
+	var results []GateResult
+	if g.MinAccuracy != nil {
+		results = append(results, GateResult{
+			Gate:      "min_accuracy",
+			Threshold: fmt.Sprintf("%.1f%%", *g.MinAccuracy),
+			Actual:    fmt.Sprintf("%.1f%%", m.Accuracy),
+			Passed:    m.Accuracy >= *g.MinAccuracy,
+		})
+	}
+	if g.MaxP95Latency != nil {
+		result := GateResult{
+			Gate:      "max_p95_latency",
+			Threshold: time.Duration(*g.MaxP95Latency).String(),
+			Actual:    m.LatencyP95.Round(time.Millisecond).String(),
+			Passed:    m.LatencyP95 <= time.Duration(*g.MaxP95Latency),
+		}
+		// Latency is only measured for live responses, so a cap cannot be
+		// verified if every response came from the cache
+		if m.LatencyP95 == 0 && m.CacheHits > 0 {
+			result.Passed = false
+			result.Detail = "no live responses to measure, as all were cached"
+		}
+		results = append(results, result)
+	}
+	if g.MaxErrors != nil {
+		results = append(results, GateResult{
+			Gate:      "max_errors",
+			Threshold: fmt.Sprintf("%d", *g.MaxErrors),
+			Actual:    fmt.Sprintf("%d", m.Errors),
+			Passed:    m.Errors <= *g.MaxErrors,
+		})
+	}
+	if g.MaxCost != nil {
+		result := GateResult{
+			Gate:      "max_cost",
+			Threshold: fmt.Sprintf("$%.4f", *g.MaxCost),
+			Actual:    fmt.Sprintf("$%.4f", m.TotalCost),
+			Passed:    m.TotalCost <= *g.MaxCost,
+		}
+		// A cost cap cannot be verified if the provider doesn't report costs
+		if m.UnpricedResponses > 0 {
+			result.Passed = false
+			result.Detail = fmt.Sprintf("cost not reported for %d responses", m.UnpricedResponses)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// GateResult is the outcome of a single quality gate.
+type GateResult struct {
+	// Gate is the name of the gate, e.g. "min_accuracy".
+	Gate string `json:"gate"`
+	// Threshold is the configured limit.
+	Threshold string `json:"threshold"`
+	// Actual is the model's value for the gated metric.
+	Actual string `json:"actual"`
+	// Passed is true if the threshold was met.
+	Passed bool `json:"passed"`
+	// Detail explains a result that is not evident from the values alone.
+	Detail string `json:"detail,omitempty"`
 }
@@ -0,0 +1,102 @@
+package types
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestListCountScores(t *testing.T) {
+	tests := []struct {
+		name                  string
+		count                 ListCount
+		precision, recall, f1 float64
+	}{
+		{name: "both empty", count: ListCount{}, precision: 1, recall: 1, f1: 1},
+		{name: "all found", count: ListCount{TruePositives: 3}, precision: 1, recall: 1, f1: 1},
+		{name: "mixed", count: ListCount{TruePositives: 2, FalsePositives: 1, FalseNegatives: 2}, precision: 2.0 / 3, recall: 0.5, f1: 4.0 / 7},
+		{name: "nothing returned", count: ListCount{FalseNegatives: 2}, precision: 0, recall: 0, f1: 0},
+		{name: "nothing expected", count: ListCount{FalsePositives: 2}, precision: 0, recall: 0, f1: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, r, f1 := tt.count.Scores()
+			if math.Abs(p-tt.precision) > 1e-9 || math.Abs(r-tt.recall) > 1e-9 || math.Abs(f1-tt.f1) > 1e-9 {
+				t.Errorf("Scores() = %v, %v, %v, want %v, %v, %v", p, r, f1, tt.precision, tt.recall, tt.f1)
+			}
+		})
+	}
+}
+
+func TestGatesEvaluate(t *testing.T) {
+	accuracy := 90.0
+	latency := Duration(2 * time.Second)
+	errors := 0
+	cost := 0.5
+	gates := Gates{MinAccuracy: &accuracy, MaxP95Latency: &latency, MaxErrors: &errors, MaxCost: &cost}
+	met := ModelMetrics{Accuracy: 95, LatencyP95: time.Second, TotalCost: 0.1}
+
+	tests := []struct {
+		name    string
+		gates   Gates
+		metrics ModelMetrics
+		// failed are the gates that should not pass.
+		failed []string
+		// detail is the expected detail of the failed gate, if any.
+		detail string
+	}{
+		{name: "no gates", gates: Gates{}, metrics: met},
+		{name: "all met", gates: gates, metrics: met},
+		{name: "limits are inclusive", gates: gates, metrics: ModelMetrics{Accuracy: 90, LatencyP95: 2 * time.Second, TotalCost: 0.5}},
+		{name: "accuracy", gates: gates, metrics: ModelMetrics{Accuracy: 89.9, LatencyP95: time.Second}, failed: []string{"min_accuracy"}},
+		{name: "latency", gates: gates, metrics: ModelMetrics{Accuracy: 95, LatencyP95: 3 * time.Second}, failed: []string{"max_p95_latency"}},
+		{name: "errors", gates: gates, metrics: ModelMetrics{Accuracy: 95, LatencyP95: time.Second, Errors: 1}, failed: []string{"max_errors"}},
+		{name: "cost", gates: gates, metrics: ModelMetrics{Accuracy: 95, LatencyP95: time.Second, TotalCost: 0.6}, failed: []string{"max_cost"}},
+		{
+			name:    "unpriced responses",
+			gates:   gates,
+			metrics: ModelMetrics{Accuracy: 95, LatencyP95: time.Second, UnpricedResponses: 2},
+			failed:  []string{"max_cost"},
+			detail:  "cost not reported for 2 responses",
+		},
+		{
+			name:    "every response cached",
+			gates:   gates,
+			metrics: ModelMetrics{Accuracy: 95, CacheHits: 4},
+			failed:  []string{"max_p95_latency"},
+			detail:  "no live responses to measure, as all were cached",
+		},
+		{name: "some responses cached", gates: gates, metrics: ModelMetrics{Accuracy: 95, LatencyP95: time.Second, CacheHits: 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := tt.gates.Evaluate(tt.metrics)
+
+			var set int
+			for _, g := range []bool{tt.gates.MinAccuracy != nil, tt.gates.MaxP95Latency != nil, tt.gates.MaxErrors != nil, tt.gates.MaxCost != nil} {
+				if g {
+					set++
+				}
+			}
+			if len(results) != set {
+				t.Fatalf("Evaluate() returned %d results, want %d", len(results), set)
+			}
+
+			var failed []string
+			for _, r := range results {
+				if !r.Passed {
+					failed = append(failed, r.Gate)
+					if r.Detail != tt.detail {
+						t.Errorf("%s detail = %q, want %q", r.Gate, r.Detail, tt.detail)
+					}
+				}
+			}
+			if !slices.Equal(failed, tt.failed) {
+				t.Errorf("failed gates = %v, want %v", failed, tt.failed)
+			}
+		})
+	}
+}
@@ -1,6 +1,15 @@
 // Package util provides common utility functions.
 package util
 
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
 // Truncate shortens a string to maxLen, adding "..." if truncated.
 func Truncate(s string, maxLen int) string {
 	runes := []rune(s)
@@ -9,3 +18,63 @@ func Truncate(s string, maxLen int) string {
 	}
 	return string(runes[:maxLen-3]) + "..."
 }
+
+// JoinPath appends an object key to a path in the syntax of field diffs,
+// e.g. "address.city".
+func JoinPath(base, key string) string {
+	if base == "" {
+		return key
+	}
+	return base + "." + key
+}
+
+// PathOrRoot returns a field path or JSON pointer, or "(root)" if it is
+// empty.
+func PathOrRoot(path string) string {
+	if path == "" {
+		return "(root)"
+	}
+	return path
+}
+
+// Describe formats a value as JSON for use in a message.
+func Describe(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
+
+// WriteFileAtomic writes data to a temporary file in the same directory and
+// renames it into place, so concurrent readers never see a partial file.
+func WriteFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// ValueStart returns the offset of the JSON value following offset in data,
+// skipping whitespace and separators.
+func ValueStart(data []byte, offset int) int {
+	for offset < len(data) && strings.IndexByte(" \t\r\n:,", data[offset]) >= 0 {
+		offset++
+	}
+	return offset
+}
+
+// LineAt returns the 1-based line number of a byte offset in data.
+func LineAt(data []byte, offset int) int {
+	return bytes.Count(data[:min(offset, len(data))], []byte("\n")) + 1
+}
@@ -0,0 +1,47 @@
+package util
+
+import "testing"
+
+func TestPaths(t *testing.T) {
+	tests := []struct {
+		base, key string
+		want      string
+	}{
+		{"", "name", "name"},
+		{"address", "city", "address.city"},
+		{"items[0]", "id", "items[0].id"},
+	}
+
+	for _, tt := range tests {
+		if got := JoinPath(tt.base, tt.key); got != tt.want {
+			t.Errorf("JoinPath(%q, %q) = %q, want %q", tt.base, tt.key, got, tt.want)
+		}
+	}
+	if got := PathOrRoot(""); got != "(root)" {
+		t.Errorf(`PathOrRoot("") = %q, want "(root)"`, got)
+	}
+	if got := PathOrRoot("/properties/a"); got != "/properties/a" {
+		t.Errorf("PathOrRoot() = %q, want the pointer unchanged", got)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	tests := []struct {
+		name string
+		v    any
+		want string
+	}{
+		{"null", nil, "null"},
+		{"string", "25", `"25"`},
+		{"number", 25.0, "25"},
+		{"array", []any{"a", true}, `["a",true]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Describe(tt.v); got != tt.want {
+				t.Errorf("Describe(%v) = %s, want %s", tt.v, got, tt.want)
+			}
+		})
+	}
+}